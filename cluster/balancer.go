@@ -28,6 +28,10 @@ const (
 	BalancerOldest = "oldest"
 	// BalancerNewest 使用最新启动的那个节点
 	BalancerNewest = "newest"
+	// BalancerLabelAffinity 优先选择标签与会话元数据匹配的节点，参考MDLabelPrefix
+	//
+	// 可用于把玩家分配到相同区域的节点
+	BalancerLabelAffinity = "labelAffinity"
 )
 
 var registeredBalancer = make(map[string]BalancerFactory)
@@ -39,6 +43,7 @@ func init() {
 	RegisterBalancer(BalancerIDHash, newIDHashBalancer)
 	RegisterBalancer(BalancerOldest, newOldestBalancer)
 	RegisterBalancer(BalancerNewest, newNewestBalancer)
+	RegisterBalancer(BalancerLabelAffinity, newLabelAffinityBalancer)
 }
 
 // Session 会话
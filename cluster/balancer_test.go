@@ -2,6 +2,9 @@ package cluster
 
 import (
 	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestAddrToint64(t *testing.T) {
@@ -36,3 +39,55 @@ func TestAddrToint64(t *testing.T) {
 		}
 	}
 }
+
+type testSession struct {
+	id string
+	md metadata.MD
+}
+
+func (s testSession) ID() string                { return s.id }
+func (s testSession) RemoteAddr() string        { return "127.0.0.1:1234" }
+func (s testSession) MetadataCopy() metadata.MD { return s.md.Copy() }
+
+func TestLabelAffinityBalancer(t *testing.T) {
+	nodes := []NodeEntry{
+		{ID: ulid.Make(), Labels: map[string]string{"zone": "eu"}},
+		{ID: ulid.Make(), Labels: map[string]string{"zone": "us"}},
+		{ID: ulid.Make()},
+	}
+	balancer := newLabelAffinityBalancer(1, nodes)
+
+	sess := testSession{id: "1", md: metadata.Pairs(MDLabelPrefix+"zone", "eu")}
+	for i := 0; i < 10; i++ {
+		if node, err := balancer.Pick(sess); err != nil {
+			t.Fatal(err)
+		} else if node.ID != nodes[0].ID {
+			t.Fatalf("expected node in zone eu, got %v", node.Labels)
+		}
+	}
+
+	// 没有匹配的节点时从所有节点中选择
+	sess = testSession{id: "2", md: metadata.Pairs(MDLabelPrefix+"zone", "asia")}
+	if _, err := balancer.Pick(sess); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	selector, err := ParseSelector("zone=eu, class=gpu")
+	if err != nil {
+		t.Fatal(err)
+	} else if selector.String() != "class=gpu,zone=eu" {
+		t.Fatalf("unexpected selector %s", selector)
+	}
+
+	if !selector.Matches(map[string]string{"zone": "eu", "class": "gpu", "mode": "pvp"}) {
+		t.Fatal("expected matched")
+	} else if selector.Matches(map[string]string{"zone": "eu"}) {
+		t.Fatal("expected not matched")
+	}
+
+	if _, err := ParseSelector("zone"); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
@@ -2,6 +2,8 @@ package cluster
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 )
@@ -53,6 +55,9 @@ type NodeEntry struct {
 
 	// git版本
 	GitVersion string `json:"git_version,omitempty"`
+
+	// 节点标签，例如区域、机型、游戏模式，可以通过Selector筛选节点
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // Validate 验证条目是否合法
@@ -126,6 +131,53 @@ type GRPCServiceDesc struct {
 
 	// Allocation 有状态节点分配方式
 	Allocation string `json:"allocation,omitempty"`
+
+	// Timeout 服务超时时间，网关调用这个服务时会代替全局的请求超时时间
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// Timeouts 方法超时时间，key为方法名称，会代替服务超时时间
+	Timeouts map[string]time.Duration `json:"timeouts,omitempty"`
+
+	// Retry 服务默认的重试策略，只对无状态服务有效
+	Retry *RetryPolicy `json:"retry,omitempty"`
+
+	// MethodRetries 方法重试策略，key为方法名称，会代替服务默认的重试策略
+	MethodRetries map[string]RetryPolicy `json:"method_retries,omitempty"`
+}
+
+// TimeoutOf 获取方法的超时时间，没有配置时返回0
+func (desc GRPCServiceDesc) TimeoutOf(method string) time.Duration {
+	if v, ok := desc.Timeouts[method]; ok {
+		return v
+	}
+	return desc.Timeout
+}
+
+// RetryPolicyOf 获取方法的重试策略
+func (desc GRPCServiceDesc) RetryPolicyOf(method string) (RetryPolicy, bool) {
+	if desc.Stateful {
+		return RetryPolicy{}, false
+	} else if p, ok := desc.MethodRetries[method]; ok {
+		return p, true
+	} else if desc.Retry != nil {
+		return *desc.Retry, true
+	}
+	return RetryPolicy{}, false
+}
+
+// sameService 是否同一个服务，不同服务使用相同的代码会导致请求被转发到错误的服务
+func (desc GRPCServiceDesc) sameService(other GRPCServiceDesc) bool {
+	return desc.Path == other.Path && desc.Name == other.Name
+}
+
+// ServiceConflict 服务代码冲突的节点
+type ServiceConflict struct {
+	NodeID   ulid.ULID `json:"node_id"`
+	NodeName string    `json:"node_name"`
+
+	// 冲突节点提供的服务
+	Name string `json:"name"`
+	Path string `json:"path"`
 }
 
 // Validate 验证条目是否合法
@@ -148,5 +200,16 @@ func (desc GRPCServiceDesc) Validate() error {
 		}
 	}
 
+	if desc.Retry != nil {
+		if err := desc.Retry.Validate(); err != nil {
+			return fmt.Errorf("invalid retry policy, %w", err)
+		}
+	}
+	for method, p := range desc.MethodRetries {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("invalid retry policy of %s, %w", method, err)
+		}
+	}
+
 	return nil
 }
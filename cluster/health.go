@@ -0,0 +1,336 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/joyparty/nodehub/logger"
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ejectOutlier 连续调用失败被摘除
+	ejectOutlier = "outlier"
+	// ejectHealthCheck 健康检查失败被摘除
+	ejectHealthCheck = "health_check"
+)
+
+// Ejection 被临时摘除的节点地址，不会被分配请求
+type Ejection struct {
+	Reason string    `json:"reason"`
+	Since  time.Time `json:"since"`
+	// 健康检查失败的节点，直到检查恢复正常之前都不会被恢复，Until为零值
+	Until time.Time `json:"until,omitempty"`
+}
+
+// OutlierConfig 异常节点检测配置
+type OutlierConfig struct {
+	// 连续出现Unavailable或DeadlineExceeded错误的次数，达到后摘除节点
+	ConsecutiveErrors int
+	// 摘除时长，到期后自动恢复
+	EjectDuration time.Duration
+	// 每个服务最多摘除的节点比例，同时限制健康检查摘除的节点，避免服务的所有节点都被摘除，0表示默认50，100表示不限制
+	MaxEjectionPercent int
+}
+
+// defaultMaxEjectionPercent 默认最多摘除一半的节点
+const defaultMaxEjectionPercent = 50
+
+// HealthCheckConfig gRPC健康检查配置，节点需要注册grpc.health.v1.Health服务，rpc.GRPCServer会自动注册
+type HealthCheckConfig struct {
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// healthChecker 被动异常检测及主动健康检查
+type healthChecker struct {
+	resolver    *grpcResolver
+	outlier     OutlierConfig
+	healthCheck HealthCheckConfig
+
+	mux      sync.Mutex
+	failures map[string]int      // endpoint => 连续失败次数
+	ejected  map[string]Ejection // endpoint => Ejection
+
+	done chan struct{}
+	once sync.Once
+}
+
+func newHealthChecker(resolver *grpcResolver) *healthChecker {
+	return &healthChecker{
+		resolver: resolver,
+		failures: map[string]int{},
+		ejected:  map[string]Ejection{},
+		done:     make(chan struct{}),
+	}
+}
+
+// isEjected 节点地址是否被摘除
+func (hc *healthChecker) isEjected(endpoint string) bool {
+	hc.mux.Lock()
+	defer hc.mux.Unlock()
+
+	_, ok := hc.ejected[endpoint]
+	return ok
+}
+
+// observe 记录调用结果
+func (hc *healthChecker) observe(endpoint string, err error) {
+	if hc.outlier.ConsecutiveErrors <= 0 {
+		return
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+	default:
+		hc.mux.Lock()
+		delete(hc.failures, endpoint)
+		hc.mux.Unlock()
+		return
+	}
+
+	hc.mux.Lock()
+	hc.failures[endpoint]++
+	failures := hc.failures[endpoint]
+	hc.mux.Unlock()
+
+	if failures >= hc.outlier.ConsecutiveErrors {
+		hc.eject(endpoint, ejectOutlier, hc.outlier.EjectDuration)
+	}
+}
+
+// unaryInterceptor 观察通过服务注册表发起的所有调用，包括网关转发的请求以及节点之间的内部调用
+func (hc *healthChecker) unaryInterceptor(endpoint string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		// 调用方主动取消或者超时，不代表节点异常，健康检查的结果单独处理
+		if ctx.Err() == nil && method != healthpb.Health_Check_FullMethodName {
+			hc.observe(endpoint, err)
+		}
+		return err
+	}
+}
+
+func (hc *healthChecker) eject(endpoint string, reason string, duration time.Duration) {
+	now := time.Now()
+	ejection := Ejection{Reason: reason, Since: now}
+	if duration > 0 {
+		ejection.Until = now.Add(duration)
+	}
+
+	services := hc.serviceEndpoints(endpoint)
+
+	hc.mux.Lock()
+	_, exists := hc.ejected[endpoint]
+	delete(hc.failures, endpoint)
+	if !exists {
+		if code, ok := hc.allowEject(services); !ok {
+			hc.mux.Unlock()
+
+			logger.Warn("too many grpc nodes ejected", "endpoint", endpoint, "reason", reason, "service", code)
+			return
+		}
+	}
+	hc.ejected[endpoint] = ejection
+	count := len(hc.ejected)
+	hc.mux.Unlock()
+
+	if duration > 0 {
+		time.AfterFunc(duration, func() {
+			hc.restore(endpoint, func(e Ejection) bool {
+				return !e.Until.IsZero() && !time.Now().Before(e.Until)
+			})
+		})
+	}
+
+	if exists {
+		return
+	}
+
+	logger.Error("eject grpc node", "endpoint", endpoint, "reason", reason, "duration", duration)
+	hc.resolver.metrics.IncrNodeEjection(reason)
+	hc.resolver.metrics.SetEjectedNodes(count)
+	hc.resolver.refreshEndpoint(endpoint)
+}
+
+// allowEject 摘除节点之后，节点提供的每个服务被摘除的节点都不能超过最大摘除比例，不允许时返回超出比例的服务代码
+//
+// 调用前需要持有hc.mux
+func (hc *healthChecker) allowEject(services map[int32]map[string]struct{}) (int32, bool) {
+	percent := hc.outlier.MaxEjectionPercent
+	if percent <= 0 {
+		percent = defaultMaxEjectionPercent
+	} else if percent >= 100 {
+		return 0, true
+	}
+
+	for code, endpoints := range services {
+		ejected := 0
+		for endpoint := range endpoints {
+			if _, ok := hc.ejected[endpoint]; ok {
+				ejected++
+			}
+		}
+
+		if (ejected+1)*100 > len(endpoints)*percent {
+			return code, false
+		}
+	}
+	return 0, true
+}
+
+// serviceEndpoints 节点地址提供的每个服务，以及提供这个服务的所有在线节点地址
+func (hc *healthChecker) serviceEndpoints(endpoint string) map[int32]map[string]struct{} {
+	services := map[int32]map[string]struct{}{}
+	hc.resolver.allNodes.Range(func(_ ulid.ULID, node NodeEntry) bool {
+		if node.GRPC.Endpoint == endpoint {
+			for _, desc := range node.GRPC.Services {
+				// code为负数的是框架内置服务，不需要服务发现
+				if desc.Code > 0 {
+					services[desc.Code] = map[string]struct{}{}
+				}
+			}
+		}
+		return true
+	})
+
+	hc.resolver.allNodes.Range(func(_ ulid.ULID, node NodeEntry) bool {
+		if node.State == NodeDown {
+			return true
+		}
+
+		for _, desc := range node.GRPC.Services {
+			if endpoints, ok := services[desc.Code]; ok {
+				endpoints[node.GRPC.Endpoint] = struct{}{}
+			}
+		}
+		return true
+	})
+	return services
+}
+
+// onlineEndpoints 所有在线节点的地址
+func (hc *healthChecker) onlineEndpoints() map[string]struct{} {
+	endpoints := map[string]struct{}{}
+	hc.resolver.allNodes.Range(func(_ ulid.ULID, node NodeEntry) bool {
+		if node.State != NodeDown && len(node.GRPC.Services) > 0 {
+			endpoints[node.GRPC.Endpoint] = struct{}{}
+		}
+		return true
+	})
+	return endpoints
+}
+
+// forget 节点离开之后清除摘除记录，健康检查摘除的节点不会自动恢复，不清除会一直留在摘除列表内
+func (hc *healthChecker) forget(endpoint string) {
+	hc.mux.Lock()
+	_, ok := hc.ejected[endpoint]
+	delete(hc.ejected, endpoint)
+	delete(hc.failures, endpoint)
+	count := len(hc.ejected)
+	hc.mux.Unlock()
+
+	if ok {
+		hc.resolver.metrics.SetEjectedNodes(count)
+	}
+}
+
+// restore 恢复满足条件的被摘除节点
+func (hc *healthChecker) restore(endpoint string, cond func(Ejection) bool) {
+	hc.mux.Lock()
+	ejection, ok := hc.ejected[endpoint]
+	if !ok || !cond(ejection) {
+		hc.mux.Unlock()
+		return
+	}
+	delete(hc.ejected, endpoint)
+	count := len(hc.ejected)
+	hc.mux.Unlock()
+
+	logger.Info("restore grpc node", "endpoint", endpoint, "reason", ejection.Reason)
+	hc.resolver.metrics.SetEjectedNodes(count)
+	hc.resolver.refreshEndpoint(endpoint)
+}
+
+// run 定时检查所有节点的健康状态
+func (hc *healthChecker) run() {
+	if hc.healthCheck.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(hc.healthCheck.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-hc.done:
+				return
+			case <-ticker.C:
+				hc.checkAll()
+			}
+		}
+	}()
+}
+
+func (hc *healthChecker) checkAll() {
+	var wg sync.WaitGroup
+	for endpoint := range hc.onlineEndpoints() {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+
+			if hc.check(endpoint) {
+				hc.restore(endpoint, func(e Ejection) bool {
+					return e.Reason == ejectHealthCheck
+				})
+			} else {
+				hc.eject(endpoint, ejectHealthCheck, 0)
+			}
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+func (hc *healthChecker) check(endpoint string) bool {
+	conn, err := hc.resolver.getConn(endpoint)
+	if err != nil {
+		return false
+	}
+
+	timeout := hc.healthCheck.Timeout
+	if timeout <= 0 {
+		timeout = hc.healthCheck.Interval
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		// 没有注册健康检查服务的节点，不做检查
+		return status.Code(err) == codes.Unimplemented
+	}
+	return resp.GetStatus() == healthpb.HealthCheckResponse_SERVING
+}
+
+func (hc *healthChecker) dump() map[string]Ejection {
+	hc.mux.Lock()
+	defer hc.mux.Unlock()
+
+	result := make(map[string]Ejection, len(hc.ejected))
+	for k, v := range hc.ejected {
+		result[k] = v
+	}
+	return result
+}
+
+func (hc *healthChecker) close() {
+	hc.once.Do(func() {
+		close(hc.done)
+	})
+}
@@ -0,0 +1,149 @@
+package cluster
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// MDLabelPrefix 会话元数据内以此为前缀的键值，会被labelAffinity负载均衡器当作节点标签选择条件
+//
+// Example: x-label-zone: eu，优先选择标签zone=eu的节点
+const MDLabelPrefix = "x-label-"
+
+// Selector 标签选择器，节点标签需要包含选择器内所有的键值才算匹配
+type Selector map[string]string
+
+// ParseSelector 解析标签选择器
+//
+// Example: zone=eu,class=gpu
+func ParseSelector(s string) (Selector, error) {
+	selector := Selector{}
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); !ok || key == "" {
+			return nil, fmt.Errorf("invalid selector %q", pair)
+		}
+		selector[key] = strings.TrimSpace(value)
+	}
+	return selector, nil
+}
+
+// Matches 节点标签是否匹配
+func (s Selector) Matches(labels map[string]string) bool {
+	for k, v := range s {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// String 与ParseSelector格式相同
+func (s Selector) String() string {
+	pairs := make([]string, 0, len(s))
+	for k, v := range s {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// FilterNodes 筛选匹配所有选择器的节点
+func FilterNodes(nodes []NodeEntry, selectors ...Selector) []NodeEntry {
+	if len(selectors) == 0 {
+		return nodes
+	}
+
+	result := make([]NodeEntry, 0, len(nodes))
+	for _, node := range nodes {
+		matched := true
+		for _, s := range selectors {
+			if !s.Matches(node.Labels) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// sessionSelector 从会话元数据中获取标签选择条件
+func sessionSelector(sess Session) Selector {
+	v, ok := sess.(interface {
+		MetadataCopy() metadata.MD
+	})
+	if !ok {
+		return nil
+	}
+
+	var selector Selector
+	for key, values := range v.MetadataCopy() {
+		if strings.HasPrefix(key, MDLabelPrefix) && len(values) > 0 {
+			if selector == nil {
+				selector = Selector{}
+			}
+			selector[strings.TrimPrefix(key, MDLabelPrefix)] = values[0]
+		}
+	}
+	return selector
+}
+
+// labelAffinityBalancer 优先选择标签与会话元数据匹配的节点，没有匹配的节点时从所有节点中选择
+//
+// 候选节点按照权重随机选择
+type labelAffinityBalancer struct {
+	serviceCode int32
+	nodes       []NodeEntry
+}
+
+func newLabelAffinityBalancer(serviceCode int32, nodes []NodeEntry) Balancer {
+	return &labelAffinityBalancer{
+		serviceCode: serviceCode,
+		nodes:       nodes,
+	}
+}
+
+func (b *labelAffinityBalancer) Pick(sess Session) (NodeEntry, error) {
+	candidates := b.nodes
+	if selector := sessionSelector(sess); len(selector) > 0 {
+		if matched := FilterNodes(b.nodes, selector); len(matched) > 0 {
+			candidates = matched
+		}
+	}
+
+	weights := make([]int, len(candidates))
+	sum := 0
+	for i, node := range candidates {
+		weight := 1
+		for _, desc := range node.GRPC.Services {
+			if desc.Code == b.serviceCode && desc.Weight > 0 {
+				weight = desc.Weight
+				break
+			}
+		}
+
+		weights[i] = weight
+		sum += weight
+	}
+
+	r := rand.Intn(sum)
+	for i, weight := range weights {
+		r -= weight
+		if r < 0 {
+			return candidates[i], nil
+		}
+	}
+	return NodeEntry{}, ErrNoNodeAvailable
+}
@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/cespare/xxhash/v2"
+	"google.golang.org/grpc/metadata"
+)
+
+// TrafficPolicy 服务流量策略，把一部分用户的请求分配到指定版本的节点上，用于灰度发布
+//
+// 用户是否属于灰度版本由用户ID哈希决定，只要策略不变，同一个用户总是被分配到相同的版本；
+// 提高百分比时，已经在灰度版本的用户不会被分配回旧版本
+type TrafficPolicy struct {
+	// 服务代码
+	ServiceCode int32 `json:"service_code"`
+
+	// 灰度版本，对应NodeEntry.GitVersion
+	Version string `json:"version"`
+
+	// 分配到灰度版本的用户百分比，0-100
+	Percent int `json:"percent"`
+
+	// 白名单用户，总是分配到灰度版本
+	Users []string `json:"users,omitempty"`
+
+	// 从会话元数据中获取用户ID的key，为空时使用会话ID
+	UserKey string `json:"user_key,omitempty"`
+}
+
+// Validate 验证策略是否合法
+func (p TrafficPolicy) Validate() error {
+	if p.ServiceCode <= 0 {
+		return errors.New("invalid service code")
+	} else if p.Version == "" {
+		return errors.New("version is empty")
+	} else if p.Percent < 0 || p.Percent > 100 {
+		return errors.New("percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// IsCanary 会话是否分配到灰度版本
+func (p TrafficPolicy) IsCanary(sess Session) bool {
+	userID := p.userID(sess)
+	if slices.Contains(p.Users, userID) {
+		return true
+	}
+
+	hash := xxhash.Sum64String(strconv.Itoa(int(p.ServiceCode)) + ":" + userID)
+	return hash%100 < uint64(p.Percent)
+}
+
+func (p TrafficPolicy) userID(sess Session) string {
+	if p.UserKey != "" {
+		if v, ok := sess.(interface {
+			MetadataCopy() metadata.MD
+		}); ok {
+			if values := v.MetadataCopy().Get(p.UserKey); len(values) > 0 && values[0] != "" {
+				return values[0]
+			}
+		}
+	}
+	return sess.ID()
+}
+
+// FilterNodes 根据会话所属版本筛选节点，对应版本没有可用节点时返回所有节点
+func (p TrafficPolicy) FilterNodes(nodes []NodeEntry, sess Session) []NodeEntry {
+	return p.filterVersion(nodes, p.IsCanary(sess))
+}
+
+// filterVersion canary为true时筛选灰度版本的节点，否则筛选其它版本的节点
+func (p TrafficPolicy) filterVersion(nodes []NodeEntry, canary bool) []NodeEntry {
+	result := make([]NodeEntry, 0, len(nodes))
+	for _, node := range nodes {
+		if (node.GitVersion == p.Version) == canary {
+			result = append(result, node)
+		}
+	}
+
+	if len(result) == 0 {
+		return nodes
+	}
+	return result
+}
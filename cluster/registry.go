@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +20,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
 	"github.com/joyparty/nodehub/proto/nh"
 )
 
@@ -25,6 +29,8 @@ var (
 	ErrNodeNotFoundOrDown = errors.New("node not found or down")
 	// ErrNoNodeAvailable 没有可用节点
 	ErrNoNodeAvailable = errors.New("no node available")
+	// ErrServiceCodeConflict 服务代码已经被其它服务使用
+	ErrServiceCodeConflict = errors.New("service code conflict")
 )
 
 // Registry 服务注册表
@@ -33,7 +39,11 @@ type Registry struct {
 
 	client       *clientv3.Client
 	keyPrefix    string
+	policyPrefix string
 	grpcResolver *grpcResolver
+	metrics      *metrics.Metrics
+	outlier      OutlierConfig
+	healthCheck  HealthCheckConfig
 
 	leaseID  clientv3.LeaseID
 	leaseTTL int64
@@ -47,6 +57,7 @@ func NewRegistry(client *clientv3.Client, opt ...func(*Registry)) (*Registry, er
 	r := &Registry{
 		client:       client,
 		keyPrefix:    "/nodehub/node",
+		policyPrefix: "/nodehub/policy",
 		grpcResolver: newGRPCResolver(),
 		leaseTTL:     10,
 		allNodes:     gokit.NewMapOf[ulid.ULID, NodeEntry](),
@@ -56,10 +67,20 @@ func NewRegistry(client *clientv3.Client, opt ...func(*Registry)) (*Registry, er
 		fn(r)
 	}
 
+	if r.metrics == nil {
+		r.metrics = metrics.New()
+	}
+	r.grpcResolver.metrics = r.metrics
+	r.grpcResolver.enableHealthCheck(r.outlier, r.healthCheck)
+
 	if err := r.runWatcher(); err != nil {
 		return nil, fmt.Errorf("run watcher, %w", err)
 	}
 
+	if err := r.runPolicyWatcher(); err != nil {
+		return nil, fmt.Errorf("run policy watcher, %w", err)
+	}
+
 	return r, nil
 }
 
@@ -129,6 +150,16 @@ func (r *Registry) initLease(ctx context.Context) error {
 	return nil
 }
 
+// updateNodeMetrics 统计每种状态的节点数量
+func (r *Registry) updateNodeMetrics() {
+	counts := map[string]int{}
+	r.allNodes.Range(func(_ ulid.ULID, entry NodeEntry) bool {
+		counts[string(entry.State)]++
+		return true
+	})
+	r.metrics.SetNodes(counts)
+}
+
 // 监听服务条目变更
 func (r *Registry) runWatcher() error {
 	events := make(chan rxgo.Item)
@@ -151,6 +182,8 @@ func (r *Registry) runWatcher() error {
 			versions[key] = kv.Version
 		}
 
+		r.metrics.IncrWatchEvent(strings.ToLower(event.String()))
+
 		var entry NodeEntry
 		if err := json.Unmarshal(kv.Value, &entry); err != nil {
 			logger.Error("unmarshal entry", "error", err)
@@ -180,6 +213,7 @@ func (r *Registry) runWatcher() error {
 
 			events <- rxgo.Of(eventDeleteNode{Entry: entry})
 		}
+		r.updateNodeMetrics()
 	}
 
 	// 监听变更
@@ -230,19 +264,133 @@ func (r *Registry) runWatcher() error {
 	return updateExists()
 }
 
+// 监听服务流量策略变更
+func (r *Registry) runPolicyWatcher() error {
+	ctx, cancel := context.WithTimeout(r.client.Ctx(), 5*time.Second)
+	defer cancel()
+
+	resp, err := r.client.Get(ctx, r.policyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("get exist policies, %w", err)
+	}
+	for _, kv := range resp.Kvs {
+		r.updatePolicy(mvccpb.PUT, kv)
+	}
+
+	go func() {
+		wCh := r.client.Watch(r.client.Ctx(), r.policyPrefix,
+			clientv3.WithPrefix(),
+			clientv3.WithRev(resp.Header.Revision+1),
+		)
+		for wResp := range wCh {
+			for _, ev := range wResp.Events {
+				r.updatePolicy(ev.Type, ev.Kv)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (r *Registry) updatePolicy(event mvccpb.Event_EventType, kv *mvccpb.KeyValue) {
+	switch event {
+	case mvccpb.PUT:
+		var policy TrafficPolicy
+		if err := json.Unmarshal(kv.Value, &policy); err != nil {
+			logger.Error("unmarshal traffic policy", "error", err)
+			return
+		}
+
+		logger.Info("update traffic policy", "policy", policy)
+		r.grpcResolver.SetPolicy(policy)
+	case mvccpb.DELETE:
+		serviceCode, err := strconv.Atoi(path.Base(string(kv.Key)))
+		if err != nil {
+			logger.Error("parse traffic policy key", "key", string(kv.Key), "error", err)
+			return
+		}
+
+		logger.Info("remove traffic policy", "serviceCode", serviceCode)
+		r.grpcResolver.RemovePolicy(int32(serviceCode))
+	}
+}
+
+// SetTrafficPolicy 设置服务流量策略，所有节点会通过etcd收到变更
+func (r *Registry) SetTrafficPolicy(ctx context.Context, policy TrafficPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("validate policy, %w", err)
+	}
+
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("marshal policy, %w", err)
+	}
+
+	_, err = r.client.Put(ctx, r.policyKey(policy.ServiceCode), string(value))
+	return err
+}
+
+// RemoveTrafficPolicy 删除服务流量策略
+func (r *Registry) RemoveTrafficPolicy(ctx context.Context, serviceCode int32) error {
+	_, err := r.client.Delete(ctx, r.policyKey(serviceCode))
+	return err
+}
+
+// GetTrafficPolicy 获取服务流量策略
+func (r *Registry) GetTrafficPolicy(serviceCode int32) (TrafficPolicy, bool) {
+	return r.grpcResolver.GetPolicy(serviceCode)
+}
+
+// TrafficPolicies 获取所有服务流量策略，按照服务代码排序
+func (r *Registry) TrafficPolicies() []TrafficPolicy {
+	policies := []TrafficPolicy{}
+	r.grpcResolver.policies.Range(func(_ int32, policy TrafficPolicy) bool {
+		policies = append(policies, policy)
+		return true
+	})
+
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].ServiceCode < policies[j].ServiceCode
+	})
+	return policies
+}
+
+func (r *Registry) policyKey(serviceCode int32) string {
+	return path.Join(r.policyPrefix, strconv.Itoa(int(serviceCode)))
+}
+
 // GetGRPCDesc 获取grpc服务描述
 func (r *Registry) GetGRPCDesc(serviceCode int32) (GRPCServiceDesc, bool) {
 	return r.grpcResolver.GetDesc(serviceCode)
 }
 
 // AllocGRPCNode 根据负载均衡策略给客户端会话分配可用节点
-func (r *Registry) AllocGRPCNode(serviceCode int32, sess Session) (nodeID ulid.ULID, err error) {
-	return r.grpcResolver.AllocNode(serviceCode, sess)
+//
+// 指定了selectors时，只在标签匹配所有选择器的节点中分配
+func (r *Registry) AllocGRPCNode(serviceCode int32, sess Session, selectors ...Selector) (nodeID ulid.ULID, err error) {
+	return r.grpcResolver.AllocNode(serviceCode, sess, selectors...)
+}
+
+// AllocGRPCNodeExcept 根据负载均衡策略在排除了指定节点之后的可用节点中分配，用于请求失败之后换一个节点重试
+func (r *Registry) AllocGRPCNodeExcept(serviceCode int32, sess Session, exclude []ulid.ULID, selectors ...Selector) (nodeID ulid.ULID, err error) {
+	return r.grpcResolver.AllocNodeExcept(serviceCode, sess, exclude, selectors...)
 }
 
 // PickGRPCNode 随机选择一个可用节点
-func (r *Registry) PickGRPCNode(serviceCode int32) (nodeID ulid.ULID, err error) {
-	return r.grpcResolver.PickNode(serviceCode)
+//
+// 指定了selectors时，只在标签匹配所有选择器的节点中选择
+func (r *Registry) PickGRPCNode(serviceCode int32, selectors ...Selector) (nodeID ulid.ULID, err error) {
+	return r.grpcResolver.PickNode(serviceCode, selectors...)
+}
+
+// IsServiceConflict 节点提供的服务是否与服务代码冲突，冲突的节点不会被分配给这个服务
+func (r *Registry) IsServiceConflict(serviceCode int32, nodeID ulid.ULID) bool {
+	return r.grpcResolver.IsConflict(serviceCode, nodeID)
+}
+
+// CheckServiceConflict 检查节点提供的服务代码是否已经被集群内其它服务使用
+func (r *Registry) CheckServiceConflict(entry NodeEntry) error {
+	return r.grpcResolver.CheckConflict(entry)
 }
 
 // GetGRPCConn 获取指定节点的grpc连接
@@ -309,6 +457,11 @@ func (r *Registry) SubscribeDelete(handler func(entry NodeEntry)) {
 		})
 }
 
+// Metrics 服务注册表的metrics，同一个节点内的组件共享
+func (r *Registry) Metrics() *metrics.Metrics {
+	return r.metrics
+}
+
 // DumpGRPCResolver 导出grpc服务解析器数据
 func (r *Registry) DumpGRPCResolver() map[string]any {
 	return r.grpcResolver.DumpData()
@@ -330,6 +483,13 @@ func WithKeyPrefix(prefix string) func(*Registry) {
 	}
 }
 
+// WithPolicyKeyPrefix 设置服务流量策略key前缀
+func WithPolicyKeyPrefix(prefix string) func(*Registry) {
+	return func(r *Registry) {
+		r.policyPrefix = prefix
+	}
+}
+
 // WithGRPCDialOptions 设置grpc.DialOption
 func WithGRPCDialOptions(options ...grpc.DialOption) func(*Registry) {
 	return func(r *Registry) {
@@ -337,6 +497,43 @@ func WithGRPCDialOptions(options ...grpc.DialOption) func(*Registry) {
 	}
 }
 
+// WithOutlierDetection 开启异常节点检测
+//
+// 节点连续出现consecutiveErrors次Unavailable或DeadlineExceeded错误后，在ejectDuration时长内不会被分配请求，
+// 网关转发的请求以及通过服务注册表获取的连接发起的调用都会被检测
+func WithOutlierDetection(consecutiveErrors int, ejectDuration time.Duration) func(*Registry) {
+	return func(r *Registry) {
+		r.outlier.ConsecutiveErrors = consecutiveErrors
+		r.outlier.EjectDuration = ejectDuration
+	}
+}
+
+// WithMaxEjectionPercent 每个服务最多摘除的节点比例，异常节点检测及健康检查都会受限制，默认50，100表示不限制
+func WithMaxEjectionPercent(percent int) func(*Registry) {
+	return func(r *Registry) {
+		r.outlier.MaxEjectionPercent = percent
+	}
+}
+
+// WithHealthCheck 开启gRPC健康检查，检查失败的节点在恢复之前不会被分配请求
+//
+// 没有注册grpc.health.v1.Health服务的节点不做检查
+func WithHealthCheck(interval, timeout time.Duration) func(*Registry) {
+	return func(r *Registry) {
+		r.healthCheck = HealthCheckConfig{
+			Interval: interval,
+			Timeout:  timeout,
+		}
+	}
+}
+
+// WithMetrics 设置metrics，默认为每个服务注册表创建独立的metrics，可以通过metrics.New()创建
+func WithMetrics(m *metrics.Metrics) func(*Registry) {
+	return func(r *Registry) {
+		r.metrics = m
+	}
+}
+
 // WithLeaseTTL 服务心跳超时，超过此时长未检测到服务心跳，即表明服务离线
 //
 // 单位 秒，默认10秒
@@ -3,9 +3,14 @@ package cluster
 import (
 	"fmt"
 	"math/rand"
+	"slices"
+	"sort"
 	"sync"
 
 	"github.com/joyparty/gokit"
+	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
+	"github.com/joyparty/nodehub/tracing"
 	"github.com/oklog/ulid/v2"
 	"github.com/samber/lo"
 	"google.golang.org/grpc"
@@ -25,29 +30,75 @@ type grpcResolver struct {
 	// 所有状态为正常的节点 serviceCode => []NodeEntry
 	okNodes *gokit.MapOf[int32, []NodeEntry]
 
+	// 服务代码冲突的节点 serviceCode => []ServiceConflict
+	conflicts *gokit.MapOf[int32, []ServiceConflict]
+
+	// 服务流量策略 serviceCode => TrafficPolicy
+	policies *gokit.MapOf[int32, TrafficPolicy]
+
 	// 每个服务的负载均衡器
-	// serviceCode => Balancer
-	serviceBalancer *gokit.MapOf[int32, Balancer]
+	// serviceCode => *balancerSet
+	serviceBalancer *gokit.MapOf[int32, *balancerSet]
 
 	// endpoint => *grpc.ClientConn
 	conns *gokit.MapOf[string, *grpc.ClientConn]
 
 	dialOptions []grpc.DialOption
+
+	// 异常节点检测及健康检查
+	health *healthChecker
+
+	metrics *metrics.Metrics
 }
 
 // newGRPCResolver 创建grpc服务发现
 func newGRPCResolver(dialOptions ...grpc.DialOption) *grpcResolver {
-	return &grpcResolver{
+	r := &grpcResolver{
 		allNodes:        gokit.NewMapOf[ulid.ULID, NodeEntry](),
 		services:        gokit.NewMapOf[int32, GRPCServiceDesc](),
 		okNodes:         gokit.NewMapOf[int32, []NodeEntry](),
-		serviceBalancer: gokit.NewMapOf[int32, Balancer](),
+		conflicts:       gokit.NewMapOf[int32, []ServiceConflict](),
+		policies:        gokit.NewMapOf[int32, TrafficPolicy](),
+		serviceBalancer: gokit.NewMapOf[int32, *balancerSet](),
 		conns:           gokit.NewMapOf[string, *grpc.ClientConn](),
 		dialOptions: append([]grpc.DialOption{
 			// 内部服务节点之间不需要加密
 			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor()),
 		}, dialOptions...),
 	}
+	r.health = newHealthChecker(r)
+	return r
+}
+
+// enableHealthCheck 开启异常节点检测及健康检查，需要在获取连接之前调用
+func (r *grpcResolver) enableHealthCheck(outlier OutlierConfig, healthCheck HealthCheckConfig) {
+	r.health.outlier = outlier
+	r.health.healthCheck = healthCheck
+	r.health.run()
+}
+
+// refreshEndpoint 节点地址被摘除或者恢复之后，更新相关服务的可用节点
+func (r *grpcResolver) refreshEndpoint(endpoint string) {
+	r.Lock()
+	defer r.Unlock()
+
+	codes := map[int32]struct{}{}
+	r.allNodes.Range(func(_ ulid.ULID, node NodeEntry) bool {
+		if node.GRPC.Endpoint == endpoint {
+			for _, desc := range node.GRPC.Services {
+				if desc.Code > 0 {
+					codes[desc.Code] = struct{}{}
+				}
+			}
+		}
+		return true
+	})
+
+	for code := range codes {
+		r.updateServiceNodes(code)
+		r.updateBalancer(code)
+	}
 }
 
 // Update 更新条目
@@ -73,7 +124,10 @@ func (r *grpcResolver) Update(node NodeEntry) {
 		// code为负数的是框架内置服务，不需要服务发现
 		if desc.Code > 0 {
 			// 网关可以一直开启不重启，所以允许新的节点配置覆盖已有配置
-			r.services.Store(desc.Code, desc)
+			// 不同服务使用了相同的代码时，以已有的服务为准，冲突的节点不会被路由
+			if current, ok := r.services.Load(desc.Code); !ok || current.sameService(desc) {
+				r.services.Store(desc.Code, desc)
+			}
 			r.updateServiceNodes(desc.Code)
 			r.updateBalancer(desc.Code)
 		}
@@ -90,6 +144,7 @@ func (r *grpcResolver) Remove(node NodeEntry) {
 		if conn, ok := r.conns.LoadAndDelete(node.GRPC.Endpoint); ok {
 			conn.Close()
 		}
+		r.health.forget(node.GRPC.Endpoint)
 	}()
 
 	r.Lock()
@@ -107,22 +162,123 @@ func (r *grpcResolver) Remove(node NodeEntry) {
 
 // updateServiceNodes 更新服务可用节点
 func (r *grpcResolver) updateServiceNodes(serviceCode int32) {
-	nodes := []NodeEntry{}
+	type provider struct {
+		node NodeEntry
+		desc GRPCServiceDesc
+	}
+
+	providers := []provider{}
 	r.allNodes.Range(func(_ ulid.ULID, node NodeEntry) bool {
-		if node.State == NodeOK &&
-			lo.SomeBy(node.GRPC.Services, func(desc GRPCServiceDesc) bool {
-				return desc.Code == serviceCode
-			}) {
-			nodes = append(nodes, node)
+		for _, desc := range node.GRPC.Services {
+			if desc.Code == serviceCode {
+				providers = append(providers, provider{node: node, desc: desc})
+				break
+			}
 		}
 		return true
 	})
 
+	// 已有服务的节点全部离开之后，由最早启动的节点提供的服务接管这个代码
+	owner, ok := r.services.Load(serviceCode)
+	if len(providers) > 0 && (!ok || !lo.SomeBy(providers, func(p provider) bool {
+		return owner.sameService(p.desc)
+	})) {
+		first := lo.MinBy(providers, func(a, b provider) bool {
+			return a.node.ID.Compare(b.node.ID) < 0
+		})
+		owner = first.desc
+		r.services.Store(serviceCode, owner)
+	}
+
+	nodes := []NodeEntry{}
+	conflicts := []ServiceConflict{}
+	for _, p := range providers {
+		if !owner.sameService(p.desc) {
+			conflicts = append(conflicts, ServiceConflict{
+				NodeID:   p.node.ID,
+				NodeName: p.node.Name,
+				Name:     p.desc.Name,
+				Path:     p.desc.Path,
+			})
+		} else if p.node.State == NodeOK && !r.health.isEjected(p.node.GRPC.Endpoint) {
+			nodes = append(nodes, p.node)
+		}
+	}
+
 	if len(nodes) == 0 {
 		r.okNodes.Delete(serviceCode)
 	} else {
 		r.okNodes.Store(serviceCode, nodes)
 	}
+
+	r.updateConflicts(serviceCode, owner, conflicts)
+}
+
+func (r *grpcResolver) updateConflicts(serviceCode int32, owner GRPCServiceDesc, conflicts []ServiceConflict) {
+	prev, _ := r.conflicts.Load(serviceCode)
+	for _, c := range conflicts {
+		if !lo.ContainsBy(prev, func(v ServiceConflict) bool { return v.NodeID == c.NodeID }) {
+			logger.Error("service code conflict",
+				"code", serviceCode,
+				"service", owner.Path,
+				"node", c.NodeID,
+				"nodeName", c.NodeName,
+				"conflict", c.Path,
+			)
+		}
+	}
+
+	if len(conflicts) == 0 {
+		r.conflicts.Delete(serviceCode)
+	} else {
+		sort.Slice(conflicts, func(i, j int) bool {
+			return conflicts[i].NodeID.Compare(conflicts[j].NodeID) < 0
+		})
+		r.conflicts.Store(serviceCode, conflicts)
+	}
+	r.metrics.SetServiceConflicts(serviceCode, len(conflicts))
+}
+
+// IsConflict 节点提供的服务是否与服务代码冲突
+func (r *grpcResolver) IsConflict(serviceCode int32, nodeID ulid.ULID) bool {
+	conflicts, _ := r.conflicts.Load(serviceCode)
+	return lo.ContainsBy(conflicts, func(c ServiceConflict) bool {
+		return c.NodeID == nodeID
+	})
+}
+
+// CheckConflict 检查节点提供的服务是否与集群内其它节点的服务代码冲突
+func (r *grpcResolver) CheckConflict(entry NodeEntry) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, desc := range entry.GRPC.Services {
+		if desc.Code <= 0 {
+			continue
+		}
+
+		owner, ok := r.services.Load(desc.Code)
+		if !ok || owner.sameService(desc) {
+			continue
+		}
+
+		// 只有仍然在线的其它节点提供了已有的服务，才算冲突
+		var conflict bool
+		r.allNodes.Range(func(id ulid.ULID, node NodeEntry) bool {
+			if id != entry.ID && lo.SomeBy(node.GRPC.Services, func(v GRPCServiceDesc) bool {
+				return v.Code == desc.Code && owner.sameService(v)
+			}) {
+				conflict = true
+				return false
+			}
+			return true
+		})
+
+		if conflict {
+			return fmt.Errorf("%w, code %d of %s is used by %s", ErrServiceCodeConflict, desc.Code, desc.Path, owner.Path)
+		}
+	}
+	return nil
 }
 
 func (r *grpcResolver) updateBalancer(serviceCode int32) {
@@ -138,33 +294,125 @@ func (r *grpcResolver) updateBalancer(serviceCode int32) {
 	if len(nodes) == 0 {
 		r.serviceBalancer.Delete(serviceCode)
 	} else {
-		r.serviceBalancer.Store(serviceCode, NewBalancer(serviceCode, nodes))
+		r.serviceBalancer.Store(serviceCode, newBalancerSet(serviceCode, nodes))
+	}
+}
+
+// balancerSet 服务的负载均衡器，以及按照标签选择器、流量策略筛选节点之后的负载均衡器
+//
+// 筛选之后的负载均衡器在第一次使用时创建，节点变更时整个balancerSet会被替换，
+// 这样自定义的有状态负载均衡器在节点不变时可以一直使用，也不需要每次请求都筛选节点
+type balancerSet struct {
+	Balancer
+
+	serviceCode int32
+	nodes       []NodeEntry
+	filtered    *gokit.MapOf[string, filteredBalancer]
+}
+
+// filteredBalancer 筛选节点之后的负载均衡器，nodes用于重试时排除已经尝试过的节点
+type filteredBalancer struct {
+	Balancer
+	nodes []NodeEntry
+}
+
+func newBalancerSet(serviceCode int32, nodes []NodeEntry) *balancerSet {
+	return &balancerSet{
+		// NewBalancer会对节点排序，不能修改共享的节点列表
+		Balancer:    NewBalancer(serviceCode, slices.Clone(nodes)),
+		serviceCode: serviceCode,
+		nodes:       nodes,
+		filtered:    gokit.NewMapOf[string, filteredBalancer](),
 	}
 }
 
+// filter 获取筛选节点之后的负载均衡器，key相同时筛选结果必须相同
+func (bs *balancerSet) filter(key string, filter func(nodes []NodeEntry) []NodeEntry) filteredBalancer {
+	if fb, ok := bs.filtered.Load(key); ok {
+		return fb
+	}
+
+	nodes := filter(slices.Clone(bs.nodes))
+	fb, _ := bs.filtered.LoadOrStore(key, filteredBalancer{
+		Balancer: NewBalancer(bs.serviceCode, slices.Clone(nodes)),
+		nodes:    nodes,
+	})
+	return fb
+}
+
 // GetDesc 获取服务描述
 func (r *grpcResolver) GetDesc(serviceCode int32) (GRPCServiceDesc, bool) {
 	return r.services.Load(serviceCode)
 }
 
-// AllocNode 根据负载均衡策略分配可用节点
-func (r *grpcResolver) AllocNode(serviceCode int32, sess Session) (nodeID ulid.ULID, err error) {
-	balancer, foundBalancer := r.serviceBalancer.Load(serviceCode)
+// AllocNode 根据负载均衡策略分配可用节点，指定了selectors时只在匹配的节点中分配
+func (r *grpcResolver) AllocNode(serviceCode int32, sess Session, selectors ...Selector) (nodeID ulid.ULID, err error) {
+	return r.AllocNodeExcept(serviceCode, sess, nil, selectors...)
+}
+
+// AllocNodeExcept 根据负载均衡策略在排除了指定节点之后的可用节点中分配，用于请求失败之后换一个节点重试
+func (r *grpcResolver) AllocNodeExcept(serviceCode int32, sess Session, exclude []ulid.ULID, selectors ...Selector) (nodeID ulid.ULID, err error) {
+	bs, foundBalancer := r.serviceBalancer.Load(serviceCode)
 	if !foundBalancer {
 		err = ErrNoNodeAvailable
 		return
 	}
 
+	balancer, nodes := bs.Balancer, bs.nodes
+	policy, hasPolicy := r.policies.Load(serviceCode)
+	if len(selectors) > 0 || hasPolicy {
+		var canary bool
+		key := lo.Reduce(selectors, func(key string, s Selector, _ int) string {
+			return key + s.String() + ";"
+		}, "")
+		if hasPolicy {
+			canary = policy.IsCanary(sess)
+			key += fmt.Sprintf("version=%s;canary=%t", policy.Version, canary)
+		}
+
+		fb := bs.filter(key, func(nodes []NodeEntry) []NodeEntry {
+			nodes = FilterNodes(nodes, selectors...)
+			if hasPolicy {
+				nodes = policy.filterVersion(nodes, canary)
+			}
+			return nodes
+		})
+		balancer, nodes = fb.Balancer, fb.nodes
+	}
+
 	node, err := balancer.Pick(sess)
+	if err == nil && slices.Contains(exclude, node.ID) {
+		// 只有重试时才会排除节点，分配到已经尝试过的节点时，在剩余的节点中重新分配
+		node, err = NewBalancer(serviceCode, lo.Filter(nodes, func(node NodeEntry, _ int) bool {
+			return !slices.Contains(exclude, node.ID)
+		})).Pick(sess)
+	}
 	if err != nil {
 		return
 	}
 	return node.ID, nil
 }
 
-// PickNode 随机选择可用节点
-func (r *grpcResolver) PickNode(serviceCode int32) (nodeID ulid.ULID, err error) {
+// SetPolicy 设置服务流量策略
+func (r *grpcResolver) SetPolicy(policy TrafficPolicy) {
+	r.policies.Store(policy.ServiceCode, policy)
+}
+
+// RemovePolicy 删除服务流量策略
+func (r *grpcResolver) RemovePolicy(serviceCode int32) {
+	r.policies.Delete(serviceCode)
+}
+
+// GetPolicy 获取服务流量策略
+func (r *grpcResolver) GetPolicy(serviceCode int32) (TrafficPolicy, bool) {
+	return r.policies.Load(serviceCode)
+}
+
+// PickNode 随机选择可用节点，指定了selectors时只在匹配的节点中选择
+func (r *grpcResolver) PickNode(serviceCode int32, selectors ...Selector) (nodeID ulid.ULID, err error) {
 	nodes, _ := r.okNodes.Load(serviceCode)
+	nodes = FilterNodes(nodes, selectors...)
+
 	if l := len(nodes); l == 0 {
 		err = ErrNoNodeAvailable
 	} else if l == 1 {
@@ -192,7 +440,12 @@ func (r *grpcResolver) getConn(endpoint string) (*grpc.ClientConn, error) {
 		return conn, nil
 	}
 
-	conn, err := grpc.NewClient(endpoint, r.dialOptions...)
+	options := r.dialOptions
+	if r.health.outlier.ConsecutiveErrors > 0 {
+		options = append(slices.Clone(options), grpc.WithChainUnaryInterceptor(r.health.unaryInterceptor(endpoint)))
+	}
+
+	conn, err := grpc.NewClient(endpoint, options...)
 	if err != nil {
 		return nil, fmt.Errorf("dial grpc, %w", err)
 	}
@@ -202,6 +455,8 @@ func (r *grpcResolver) getConn(endpoint string) (*grpc.ClientConn, error) {
 }
 
 func (r *grpcResolver) Close() {
+	r.health.close()
+
 	r.conns.Range(func(key string, value *grpc.ClientConn) bool {
 		_ = value.Close()
 		r.conns.Delete(key)
@@ -230,9 +485,24 @@ func (r *grpcResolver) DumpData() map[string]any {
 		return true
 	})
 
+	conflicts := map[int32][]ServiceConflict{}
+	r.conflicts.Range(func(key int32, value []ServiceConflict) bool {
+		conflicts[key] = value
+		return true
+	})
+
+	policies := map[int32]TrafficPolicy{}
+	r.policies.Range(func(key int32, value TrafficPolicy) bool {
+		policies[key] = value
+		return true
+	})
+
 	return map[string]any{
-		"services": service,
-		"allNodes": allNodes,
-		"okNodes":  okNodes,
+		"services":  service,
+		"allNodes":  allNodes,
+		"okNodes":   okNodes,
+		"conflicts": conflicts,
+		"policies":  policies,
+		"ejected":   r.health.dump(),
 	}
 }
@@ -1,7 +1,9 @@
 package cluster
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -9,10 +11,16 @@ import (
 	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/joyparty/gokit"
+	"github.com/joyparty/nodehub/metrics"
 	"github.com/oklog/ulid/v2"
 	"github.com/samber/lo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 func TestGRPCResolver(t *testing.T) {
@@ -54,6 +62,295 @@ func TestGRPCResolver(t *testing.T) {
 	})
 }
 
+func TestGRPCResolverConflict(t *testing.T) {
+	resolver := newGRPCResolver()
+
+	room := newTestEntry("127.0.0.1:9000", "room.Room")
+	chat := newTestEntry("127.0.0.1:9000", "chat.Chat")
+
+	resolver.Update(room)
+	if err := resolver.CheckConflict(chat); !errors.Is(err, ErrServiceCodeConflict) {
+		t.Fatalf("expected conflict error, got %v", err)
+	} else if err := resolver.CheckConflict(room); err != nil {
+		t.Fatalf("unexpected conflict, %v", err)
+	}
+
+	// 冲突的节点不会被路由
+	resolver.Update(chat)
+	if desc, _ := resolver.GetDesc(1); desc.Path != "/room.Room" {
+		t.Fatalf("service overridden by conflict node, %s", desc.Path)
+	} else if nodes, _ := resolver.okNodes.Load(1); !compareNodes(nodes, []NodeEntry{room}) {
+		t.Fatalf("conflict node in ok nodes")
+	} else if !resolver.IsConflict(1, chat.ID) || resolver.IsConflict(1, room.ID) {
+		t.Fatalf("unexpected conflict state")
+	}
+
+	// 原有服务的节点离开之后，冲突节点接管服务代码
+	resolver.Remove(room)
+	if desc, _ := resolver.GetDesc(1); desc.Path != "/chat.Chat" {
+		t.Fatalf("service not taken over, %s", desc.Path)
+	} else if nodes, _ := resolver.okNodes.Load(1); !compareNodes(nodes, []NodeEntry{chat}) {
+		t.Fatalf("taken over node not in ok nodes")
+	} else if resolver.IsConflict(1, chat.ID) {
+		t.Fatalf("conflict not cleared")
+	}
+}
+
+func TestGRPCResolverPolicy(t *testing.T) {
+	resolver := newGRPCResolver()
+
+	stable, canary := newTestEntry("127.0.0.1:9000", "room.Room"), newTestEntry("127.0.0.1:9000", "room.Room")
+	stable.GitVersion, canary.GitVersion = "v1", "v2"
+	resolver.Update(stable)
+	resolver.Update(canary)
+
+	resolver.SetPolicy(TrafficPolicy{
+		ServiceCode: 1,
+		Version:     "v2",
+		Percent:     30,
+		Users:       []string{"vip"},
+	})
+
+	alloc := func(userID string) ulid.ULID {
+		nodeID, err := resolver.AllocNode(1, testSession{id: userID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nodeID
+	}
+
+	if alloc("vip") != canary.ID {
+		t.Fatal("whitelist user not routed to canary version")
+	}
+
+	var canaries int
+	for i := 0; i < 1000; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+
+		// 同一个用户总是分配到相同的版本
+		nodeID := alloc(userID)
+		for j := 0; j < 3; j++ {
+			if alloc(userID) != nodeID {
+				t.Fatalf("user %s switched version", userID)
+			}
+		}
+
+		if nodeID == canary.ID {
+			canaries++
+		}
+	}
+	if canaries < 200 || canaries > 400 {
+		t.Fatalf("unexpected canary users %d of 1000", canaries)
+	}
+
+	// 灰度版本没有可用节点时使用所有节点
+	resolver.Remove(canary)
+	if alloc("vip") != stable.ID {
+		t.Fatal("fallback to stable version failed")
+	}
+}
+
+func TestGRPCResolverFilteredBalancer(t *testing.T) {
+	resolver := newGRPCResolver()
+	for i := 0; i < 3; i++ {
+		entry := newTestEntry(fmt.Sprintf("127.0.0.1:%d", 9000+i), "room.Room")
+		entry.Labels = map[string]string{"zone": "eu"}
+		entry.GRPC.Services[0].Balancer = "testSequence"
+		resolver.Update(entry)
+	}
+	sequenceBalancers = 0
+
+	// 筛选之后的负载均衡器会被复用，有状态的负载均衡器可以依次分配所有节点
+	selected := map[ulid.ULID]struct{}{}
+	for i := 0; i < 3; i++ {
+		nodeID, err := resolver.AllocNode(1, testSession{id: "user"}, Selector{"zone": "eu"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		selected[nodeID] = struct{}{}
+	}
+	if len(selected) != 3 {
+		t.Fatalf("expected 3 nodes selected, got %d", len(selected))
+	} else if sequenceBalancers != 1 {
+		t.Fatalf("expected 1 filtered balancer created, got %d", sequenceBalancers)
+	}
+
+	// 节点变更之后重新筛选
+	entry := newTestEntry("127.0.0.1:9003", "room.Room")
+	entry.GRPC.Services[0].Balancer = "testSequence"
+	resolver.Update(entry)
+	sequenceBalancers = 0
+
+	for i := 0; i < 3; i++ {
+		nodeID, err := resolver.AllocNode(1, testSession{id: "user"}, Selector{"zone": "eu"})
+		if err != nil {
+			t.Fatal(err)
+		} else if nodeID == entry.ID {
+			t.Fatal("node without matched labels selected")
+		}
+	}
+	if sequenceBalancers != 1 {
+		t.Fatalf("expected 1 filtered balancer created, got %d", sequenceBalancers)
+	}
+}
+
+func TestGRPCResolverOutlier(t *testing.T) {
+	resolver := newGRPCResolver()
+	resolver.enableHealthCheck(OutlierConfig{ConsecutiveErrors: 3, EjectDuration: 100 * time.Millisecond}, HealthCheckConfig{})
+	defer resolver.Close()
+
+	a, b := newTestEntry("127.0.0.1:9000", "room.Room"), newTestEntry("127.0.0.1:9001", "room.Room")
+	resolver.Update(a)
+	resolver.Update(b)
+
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	// 成功的调用会重置连续失败次数
+	resolver.health.observe(a.GRPC.Endpoint, unavailable)
+	resolver.health.observe(a.GRPC.Endpoint, unavailable)
+	resolver.health.observe(a.GRPC.Endpoint, nil)
+	resolver.health.observe(a.GRPC.Endpoint, unavailable)
+	if resolver.health.isEjected(a.GRPC.Endpoint) {
+		t.Fatal("ejected without consecutive errors")
+	}
+
+	resolver.health.observe(a.GRPC.Endpoint, unavailable)
+	resolver.health.observe(a.GRPC.Endpoint, status.Error(codes.DeadlineExceeded, "timeout"))
+	if nodes, _ := resolver.okNodes.Load(1); !compareNodes(slices.Clone(nodes), []NodeEntry{b}) {
+		t.Fatal("outlier node not ejected")
+	}
+	for i := 0; i < 10; i++ {
+		if nodeID, err := resolver.AllocNode(1, testSession{id: fmt.Sprint(i)}); err != nil || nodeID != b.ID {
+			t.Fatalf("ejected node allocated, %v", err)
+		}
+	}
+
+	// 到期后自动恢复
+	time.Sleep(200 * time.Millisecond)
+	if nodes, _ := resolver.okNodes.Load(1); !compareNodes(slices.Clone(nodes), []NodeEntry{a, b}) {
+		t.Fatal("ejected node not restored")
+	}
+}
+
+func TestGRPCResolverEjection(t *testing.T) {
+	resolver := newGRPCResolver()
+	resolver.enableHealthCheck(OutlierConfig{ConsecutiveErrors: 1, EjectDuration: time.Minute}, HealthCheckConfig{})
+	defer resolver.Close()
+
+	rooms := make([]NodeEntry, 8)
+	for i := range rooms {
+		rooms[i] = newTestEntry(fmt.Sprintf("127.0.0.1:%d", 9000+i), "room.Room")
+		resolver.Update(rooms[i])
+	}
+	chats := make([]NodeEntry, 2)
+	for i := range chats {
+		chats[i] = newTestEntry(fmt.Sprintf("127.0.0.1:%d", 9100+i), "chat.Chat")
+		chats[i].GRPC.Services[0].Code = 2
+		resolver.Update(chats[i])
+	}
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	// 默认每个服务最多摘除一半的节点，按照服务各自的节点数量计算
+	for _, entry := range chats {
+		resolver.health.observe(entry.GRPC.Endpoint, unavailable)
+	}
+	if ejected := resolver.health.dump(); len(ejected) != 1 {
+		t.Fatalf("expected 1 ejected chat node, got %d", len(ejected))
+	} else if _, err := resolver.AllocNode(2, nil); err != nil {
+		t.Fatalf("alloc chat node, %v", err)
+	}
+
+	for _, entry := range rooms {
+		resolver.health.observe(entry.GRPC.Endpoint, unavailable)
+	}
+	if ejected := resolver.health.dump(); len(ejected) != 5 {
+		t.Fatalf("expected 5 ejected nodes, got %d", len(ejected))
+	}
+
+	// 健康检查的失败不计入连续失败次数
+	interceptor := resolver.health.unaryInterceptor(chats[1].GRPC.Endpoint)
+	resolver.health.forget(chats[0].GRPC.Endpoint)
+	_ = interceptor(context.Background(), healthpb.Health_Check_FullMethodName, nil, nil, nil,
+		func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			return unavailable
+		})
+	if resolver.health.isEjected(chats[1].GRPC.Endpoint) {
+		t.Fatal("health check failure counted as outlier")
+	}
+
+	// 健康检查摘除的节点不会自动恢复，节点离开之后需要清除
+	resolver.health.eject(chats[1].GRPC.Endpoint, ejectHealthCheck, 0)
+	resolver.Remove(chats[1])
+	if resolver.health.isEjected(chats[1].GRPC.Endpoint) {
+		t.Fatal("ejection of removed node not purged")
+	}
+}
+
+func TestGRPCResolverMetrics(t *testing.T) {
+	// 每个resolver使用独立的metrics，可以并行执行
+	for _, conflicts := range []int{1, 3} {
+		conflicts := conflicts
+		t.Run(fmt.Sprintf("conflicts-%d", conflicts), func(t *testing.T) {
+			t.Parallel()
+
+			resolver := newGRPCResolver()
+			resolver.metrics = metrics.New()
+			resolver.enableHealthCheck(OutlierConfig{ConsecutiveErrors: 1, EjectDuration: time.Minute, MaxEjectionPercent: 100}, HealthCheckConfig{})
+			defer resolver.Close()
+
+			resolver.Update(newTestEntry("127.0.0.1:9000", "room.Room"))
+			for i := 0; i < conflicts; i++ {
+				resolver.Update(newTestEntry("127.0.0.1:9000", fmt.Sprintf("chat%d.Chat", i)))
+			}
+			resolver.health.observe("127.0.0.1:9000", status.Error(codes.Unavailable, "unavailable"))
+
+			if v := gatherMetric(t, resolver.metrics, "service_code_conflicts"); v != float64(conflicts) {
+				t.Fatalf("service_code_conflicts, expected %d, got %v", conflicts, v)
+			} else if v := gatherMetric(t, resolver.metrics, "grpc_node_ejected"); v != 1 {
+				t.Fatalf("grpc_node_ejected, expected 1, got %v", v)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Millisecond, MaxBackoff: 30 * time.Millisecond}
+	for attempt, expected := range []time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 30 * time.Millisecond, 4: 30 * time.Millisecond} {
+		if attempt > 0 && policy.BackoffOf(attempt) != expected {
+			t.Fatalf("attempt %d expected backoff %s, got %s", attempt, expected, policy.BackoffOf(attempt))
+		}
+	}
+
+	if !policy.IsRetryable(status.Error(codes.Unavailable, "")) || policy.IsRetryable(status.Error(codes.Internal, "")) {
+		t.Fatal("unexpected default retryable codes")
+	}
+
+	resolver := newGRPCResolver()
+	entries := genTestEntries(3)
+	for i := range entries {
+		entries[i].GRPC.Services = []GRPCServiceDesc{
+			{Name: "room.Room", Code: 1, Path: "/room.Room", Public: true, Balancer: BalancerRandom},
+		}
+		resolver.Update(entries[i])
+	}
+
+	// 重试时不会分配到已经尝试过的节点
+	tried := []ulid.ULID{}
+	for range entries {
+		nodeID, err := resolver.AllocNodeExcept(1, testSession{id: "test"}, tried)
+		if err != nil {
+			t.Fatal(err)
+		} else if slices.Contains(tried, nodeID) {
+			t.Fatalf("tried node %s allocated", nodeID)
+		}
+		tried = append(tried, nodeID)
+	}
+
+	if _, err := resolver.AllocNodeExcept(1, testSession{id: "test"}, tried); !errors.Is(err, ErrNoNodeAvailable) {
+		t.Fatalf("expected no node available, got %v", err)
+	}
+}
+
 func updateResolver(resolver *grpcResolver, update []NodeEntry, remove []NodeEntry) {
 	var wg sync.WaitGroup
 	for i := 0; i < len(update); i++ {
@@ -124,6 +421,21 @@ func getExpectedServices(entries []NodeEntry) []int32 {
 	return lo.Uniq(services)
 }
 
+// newTestEntry 只提供一个服务的节点，服务代码为1
+func newTestEntry(endpoint, path string) NodeEntry {
+	return NodeEntry{
+		ID:    ulid.Make(),
+		Name:  path,
+		State: NodeOK,
+		GRPC: GRPCEntry{
+			Endpoint: endpoint,
+			Services: []GRPCServiceDesc{
+				{Name: path, Code: 1, Path: "/" + path, Public: true, Balancer: BalancerRandom},
+			},
+		},
+	}
+}
+
 func genTestEntries(count int) []NodeEntry {
 	entries := make([]NodeEntry, 0, count)
 
@@ -185,3 +497,46 @@ func printJSON(v any) {
 	data := gokit.MustReturn(json.MarshalIndent(v, "", "  "))
 	fmt.Fprintln(os.Stderr, string(data))
 }
+
+func gatherMetric(t *testing.T, m *metrics.Metrics, name string) float64 {
+	families, err := m.Registry().Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			if g := metric.GetGauge(); g != nil {
+				return g.GetValue()
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+// sequenceBalancers 已经创建的sequenceBalancer数量
+var sequenceBalancers int
+
+func init() {
+	RegisterBalancer("testSequence", func(serviceCode int32, nodes []NodeEntry) Balancer {
+		sequenceBalancers++
+		return &sequenceBalancer{nodes: nodes}
+	})
+}
+
+// sequenceBalancer 依次分配每个节点的有状态负载均衡器
+type sequenceBalancer struct {
+	nodes []NodeEntry
+	next  int
+}
+
+func (b *sequenceBalancer) Pick(sess Session) (NodeEntry, error) {
+	node := b.nodes[b.next%len(b.nodes)]
+	b.next++
+	return node, nil
+}
@@ -0,0 +1,62 @@
+package cluster
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy 请求重试策略，网关调用无状态服务失败时，在请求超时时间内换一个节点重试
+//
+// 只应该给幂等的方法配置重试策略
+type RetryPolicy struct {
+	// 最大尝试次数，包括第一次请求
+	MaxAttempts int `json:"max_attempts"`
+
+	// 第一次重试之前的等待时间，之后每次翻倍
+	Backoff time.Duration `json:"backoff,omitempty"`
+
+	// 重试等待时间上限，为0时不限制
+	MaxBackoff time.Duration `json:"max_backoff,omitempty"`
+
+	// 可以重试的错误码，为空时只重试Unavailable
+	RetryableCodes []codes.Code `json:"retryable_codes,omitempty"`
+}
+
+// Validate 验证策略是否合法
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 2 {
+		return errors.New("max attempts must be at least 2")
+	} else if p.Backoff < 0 || p.MaxBackoff < 0 {
+		return errors.New("backoff must not be negative")
+	}
+	return nil
+}
+
+// IsRetryable 错误是否可以重试
+func (p RetryPolicy) IsRetryable(err error) bool {
+	code := status.Code(err)
+	if len(p.RetryableCodes) == 0 {
+		return code == codes.Unavailable
+	}
+	return slices.Contains(p.RetryableCodes, code)
+}
+
+// BackoffOf 第attempt次请求失败之后，重试之前的等待时间
+func (p RetryPolicy) BackoffOf(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d > 0; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,286 @@
+// nodehub-bench 网关压力测试工具
+//
+// 启动大量模拟客户端连接网关，按照场景文件依次调用服务方法或等待下行消息，
+// 结束后输出每个步骤的延迟分位数、吞吐量以及错误码统计
+//
+// Example:
+//
+//	nodehub-bench -url tcp://127.0.0.1:9000 -scenario scenario.json -descriptors api.pb -clients 2000 -duration 1m
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/joyparty/nodehub/component/gateway/client"
+	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/proto/nh"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+var config = struct {
+	URL         string
+	Scenario    string
+	Descriptors string
+	Clients     int
+	Ramp        int
+	Duration    time.Duration
+	Iterations  int
+	Timeout     time.Duration
+	ALPN        string
+	Insecure    bool
+	Verbose     bool
+}{}
+
+func init() {
+	flag.StringVar(&config.URL, "url", "", "gateway entrance, tcp://, ws://, quic:// or kcp://, comma separated")
+	flag.StringVar(&config.Scenario, "scenario", "", "scenario json file")
+	flag.StringVar(&config.Descriptors, "descriptors", "", "file descriptor set, built by protoc --include_imports --descriptor_set_out")
+	flag.IntVar(&config.Clients, "clients", 100, "number of simulated clients")
+	flag.IntVar(&config.Ramp, "ramp", 100, "clients started per second, 0 means all at once")
+	flag.DurationVar(&config.Duration, "duration", 0, "run duration, 0 means run iterations only")
+	flag.IntVar(&config.Iterations, "iterations", 1, "scenario iterations per client, 0 means repeat until duration")
+	flag.DurationVar(&config.Timeout, "timeout", 5*time.Second, "default timeout of each step")
+	flag.StringVar(&config.ALPN, "alpn", "", "quic tls next protos, comma separated")
+	flag.BoolVar(&config.Insecure, "insecure", false, "skip tls certificate verification")
+	flag.BoolVar(&config.Verbose, "verbose", false, "print client logs")
+}
+
+func main() {
+	flag.Parse()
+
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if config.URL == "" || config.Scenario == "" {
+		flag.Usage()
+		return fmt.Errorf("url and scenario are required")
+	} else if config.Duration <= 0 && config.Iterations <= 0 {
+		return fmt.Errorf("either duration or iterations must be set")
+	}
+
+	if config.Verbose {
+		logger.SetLogger(slog.Default())
+	}
+
+	var files *protoregistry.Files
+	if config.Descriptors != "" {
+		var err error
+		if files, err = loadDescriptors(config.Descriptors); err != nil {
+			return err
+		}
+	}
+
+	scenario, err := loadScenario(config.Scenario, files)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if config.Duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	b := &bench{
+		scenario: scenario,
+		stats:    newStats(),
+	}
+
+	start := time.Now()
+	b.start(ctx)
+	elapsed := time.Since(start)
+
+	fmt.Printf("\nclients: %d, connected: %d, elapsed: %s\n\n", config.Clients, b.connected.Load(), elapsed.Round(time.Millisecond))
+	b.stats.report(os.Stdout, elapsed)
+	return nil
+}
+
+type bench struct {
+	scenario  *Scenario
+	stats     *stats
+	connected atomic.Int32
+	active    atomic.Int32
+}
+
+func (b *bench) start(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	go b.progress(ctx)
+
+	var interval time.Duration
+	if config.Ramp > 0 {
+		interval = time.Second / time.Duration(config.Ramp)
+	}
+
+	for i := 0; i < config.Clients; i++ {
+		if interval > 0 && i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			b.runClient(ctx, id)
+		}(i)
+	}
+}
+
+// progress 定时输出执行进度
+func (b *bench) progress(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ok, failed := b.stats.total()
+			fmt.Fprintf(os.Stderr, "active clients: %d, ok: %d, errors: %d\n", b.active.Load(), ok, failed)
+		}
+	}
+}
+
+func (b *bench) runClient(ctx context.Context, id int) {
+	entrances := strings.Split(config.URL, ",")
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.Insecure,
+	}
+	if config.ALPN != "" {
+		tlsConfig.NextProtos = strings.Split(config.ALPN, ",")
+	}
+
+	// 压测时不重连，断开的连接直接计入错误
+	start := time.Now()
+	c, err := client.New(entrances[id%len(entrances)],
+		client.WithReconnect(0, 0),
+		client.WithTLSConfig(tlsConfig),
+	)
+	b.stats.record("connect", time.Since(start), err)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	b.connected.Add(1)
+	b.active.Add(1)
+	defer b.active.Add(-1)
+
+	pushes := make(chan *nh.Reply, 1024)
+	c.SetDefaultHandler(func(reply *nh.Reply) {
+		select {
+		case pushes <- reply:
+		default:
+		}
+	})
+
+	for i := 0; config.Iterations <= 0 || i < config.Iterations; i++ {
+		for _, step := range b.scenario.Steps {
+			if step.Once && i > 0 {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			if !b.runStep(ctx, c, step, pushes, templateVars{Client: id, Iteration: i}) {
+				return
+			}
+
+			if step.Sleep > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Duration(step.Sleep)):
+				}
+			}
+		}
+	}
+}
+
+// runStep 执行单个步骤，返回false表示连接已经断开
+func (b *bench) runStep(ctx context.Context, c *client.Client, step *Step, pushes <-chan *nh.Reply, vars templateVars) bool {
+	if step.Push == nil && step.Method == "" {
+		return true
+	}
+
+	timeout := config.Timeout
+	if step.Timeout > 0 {
+		timeout = time.Duration(step.Timeout)
+	}
+
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	if step.Push != nil {
+		err = waitPush(stepCtx, pushes, step.Push)
+	} else {
+		err = invoke(stepCtx, c, step, vars)
+	}
+
+	// 压测结束时被中断的步骤不计入统计
+	if ctx.Err() != nil {
+		return false
+	}
+
+	b.stats.record(step.Name, time.Since(start), err)
+	return c.State() != client.StateClosed
+}
+
+func invoke(ctx context.Context, c *client.Client, step *Step, vars templateVars) error {
+	in, err := step.newRequest(vars)
+	if err != nil {
+		return err
+	}
+
+	var opts []client.CallOption
+	if step.Node != "" {
+		opts = append(opts, client.WithNode(step.Node))
+	}
+
+	if step.NoReply {
+		return c.Call(step.Service, step.Method, in, append(opts, client.WithNoReply())...)
+	}
+	return c.Invoke(ctx, step.Service, step.Method, in, nil, opts...)
+}
+
+func waitPush(ctx context.Context, pushes <-chan *nh.Reply, match *PushMatch) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait push timeout")
+		case reply := <-pushes:
+			if reply.GetServiceCode() == match.Service && reply.GetCode() == match.Code {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Scenario 每个模拟客户端依次执行的步骤
+type Scenario struct {
+	Steps []*Step `json:"steps"`
+}
+
+// Step 场景步骤，调用服务方法、等待下行消息或者暂停
+//
+// Example:
+//
+//	{"name": "login", "once": true, "service": 1, "method": "Login", "type": "auth.LoginRequest", "payload": {"name": "bot-{{.Client}}"}}
+//	{"service": 2, "method": "Say", "raw": "CgVoZWxsbw==", "no_reply": true}
+//	{"push": {"service": 2, "code": 3}, "timeout": "5s"}
+//	{"sleep": "100ms"}
+type Step struct {
+	Name string `json:"name"`
+	// 只在第一轮执行，例如登录
+	Once bool `json:"once"`
+
+	Service int32  `json:"service"`
+	Method  string `json:"method"`
+	Node    string `json:"node"`
+	NoReply bool   `json:"no_reply"`
+	// 请求消息类型全名，payload会以protojson格式解码为这个类型
+	Type string `json:"type"`
+	// 请求消息json模板，可以使用{{.Client}}、{{.Iteration}}变量
+	Payload json.RawMessage `json:"payload"`
+	// base64编码的protobuf请求数据，没有类型描述信息时使用
+	Raw string `json:"raw"`
+
+	// 等待指定的下行消息
+	Push *PushMatch `json:"push"`
+
+	Timeout Duration `json:"timeout"`
+	// 步骤执行之后暂停的时间
+	Sleep Duration `json:"sleep"`
+
+	msgType  protoreflect.MessageDescriptor
+	tmpl     *template.Template
+	rawBytes []byte
+}
+
+// PushMatch 下行消息匹配条件
+type PushMatch struct {
+	Service int32 `json:"service"`
+	Code    int32 `json:"code"`
+}
+
+// Duration 可以使用"5s"格式解析的时间间隔
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// templateVars 请求消息模板变量
+type templateVars struct {
+	Client    int
+	Iteration int
+}
+
+func loadScenario(file string, files *protoregistry.Files) (*Scenario, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read scenario, %w", err)
+	}
+
+	s := &Scenario{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("decode scenario, %w", err)
+	} else if len(s.Steps) == 0 {
+		return nil, errors.New("empty scenario")
+	}
+
+	for i, step := range s.Steps {
+		if err := step.init(files); err != nil {
+			return nil, fmt.Errorf("step %d, %w", i, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *Step) init(files *protoregistry.Files) error {
+	switch {
+	case s.Push != nil:
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("push %d/%d", s.Push.Service, s.Push.Code)
+		}
+		return nil
+	case s.Method == "":
+		if s.Sleep <= 0 {
+			return errors.New("step must have method, push or sleep")
+		}
+		return nil
+	}
+
+	if s.Name == "" {
+		s.Name = fmt.Sprintf("%d/%s", s.Service, s.Method)
+	}
+
+	if s.Raw != "" {
+		data, err := base64.StdEncoding.DecodeString(s.Raw)
+		if err != nil {
+			return fmt.Errorf("decode raw payload, %w", err)
+		}
+		s.rawBytes = data
+		return nil
+	}
+
+	if s.Type == "" {
+		if len(s.Payload) > 0 {
+			return errors.New("payload requires message type")
+		}
+		return nil
+	} else if files == nil {
+		return errors.New("message type requires descriptors")
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(s.Type))
+	if err != nil {
+		return fmt.Errorf("find message type %s, %w", s.Type, err)
+	}
+
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return fmt.Errorf("%s is not a message", s.Type)
+	}
+	s.msgType = md
+
+	payload := string(s.Payload)
+	if payload == "" {
+		payload = "{}"
+	}
+
+	tmpl, err := template.New(s.Name).Option("missingkey=error").Parse(payload)
+	if err != nil {
+		return fmt.Errorf("parse payload template, %w", err)
+	}
+	s.tmpl = tmpl
+	return nil
+}
+
+// newRequest 根据模板构造请求消息
+func (s *Step) newRequest(vars templateVars) (proto.Message, error) {
+	if s.msgType == nil {
+		msg := &emptypb.Empty{}
+		if len(s.rawBytes) > 0 {
+			// 以unknown fields方式携带原始数据，序列化时会原样输出
+			msg.ProtoReflect().SetUnknown(s.rawBytes)
+		}
+		return msg, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := s.tmpl.Execute(buf, vars); err != nil {
+		return nil, fmt.Errorf("execute payload template, %w", err)
+	}
+
+	msg := dynamicpb.NewMessage(s.msgType)
+	if err := protojson.Unmarshal(buf.Bytes(), msg); err != nil {
+		return nil, fmt.Errorf("decode payload, %w", err)
+	}
+	return msg, nil
+}
+
+// loadDescriptors 加载protoc --include_imports --descriptor_set_out生成的描述文件
+func loadDescriptors(file string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read descriptors, %w", err)
+	}
+
+	fds := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, fds); err != nil {
+		return nil, fmt.Errorf("unmarshal descriptors, %w", err)
+	}
+
+	files, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, fmt.Errorf("build descriptors, %w", err)
+	}
+	return files, nil
+}
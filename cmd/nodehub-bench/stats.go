@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joyparty/nodehub/component/gateway/client"
+	"google.golang.org/grpc/status"
+)
+
+// stats 按步骤统计延迟及错误
+type stats struct {
+	mux   sync.Mutex
+	steps map[string]*stepStats
+	order []string
+}
+
+type stepStats struct {
+	latencies []time.Duration
+	errors    map[string]int
+}
+
+func newStats() *stats {
+	return &stats{
+		steps: map[string]*stepStats{},
+	}
+}
+
+func (s *stats) record(name string, d time.Duration, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	ss, ok := s.steps[name]
+	if !ok {
+		ss = &stepStats{errors: map[string]int{}}
+		s.steps[name] = ss
+		s.order = append(s.order, name)
+	}
+
+	if err != nil {
+		ss.errors[errorLabel(err)]++
+	} else {
+		ss.latencies = append(ss.latencies, d)
+	}
+}
+
+// total 成功及失败的总次数
+func (s *stats) total() (ok, failed int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for _, ss := range s.steps {
+		ok += len(ss.latencies)
+		for _, n := range ss.errors {
+			failed += n
+		}
+	}
+	return
+}
+
+func (s *stats) report(w io.Writer, elapsed time.Duration) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "step\tok\terrors\trps\tp50\tp90\tp99\tmax\t")
+
+	for _, name := range s.order {
+		ss := s.steps[name]
+		sort.Slice(ss.latencies, func(i, j int) bool { return ss.latencies[i] < ss.latencies[j] })
+
+		failed := 0
+		for _, n := range ss.errors {
+			failed += n
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n",
+			name,
+			len(ss.latencies),
+			failed,
+			float64(len(ss.latencies))/elapsed.Seconds(),
+			percentile(ss.latencies, 0.5),
+			percentile(ss.latencies, 0.9),
+			percentile(ss.latencies, 0.99),
+			percentile(ss.latencies, 1),
+		)
+	}
+	_ = tw.Flush()
+
+	for _, name := range s.order {
+		ss := s.steps[name]
+		if len(ss.errors) == 0 {
+			continue
+		}
+
+		labels := make([]string, 0, len(ss.errors))
+		for label := range ss.errors {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		fmt.Fprintf(w, "\n%s errors:\n", name)
+		for _, label := range labels {
+			fmt.Fprintf(w, "  %s: %d\n", label, ss.errors[label])
+		}
+	}
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i].Round(time.Microsecond)
+}
+
+// errorLabel RPCError以grpc状态码统计，其它错误归入固定的几个分类，避免错误描述内的地址等信息导致分类过多
+func errorLabel(err error) string {
+	var netErr net.Error
+	if s, ok := status.FromError(err); ok {
+		return s.Code().String()
+	} else if errors.Is(err, client.ErrClosed) || errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) {
+		return "Disconnected"
+	} else if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return "Timeout"
+	} else if errors.As(err, &netErr) {
+		return "Network"
+	}
+	return "Other"
+}
@@ -0,0 +1,501 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joyparty/nodehub/cluster"
+	"github.com/joyparty/nodehub/multicast"
+	"github.com/joyparty/nodehub/proto/nh"
+	"github.com/nats-io/nats.go"
+	"github.com/oklog/ulid/v2"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func listNodes(_ context.Context, r *cluster.Registry, _ []string) error {
+	nodes := allNodes(r)
+
+	t := newTable(nodes, "ID", "NAME", "STATE", "ENTRANCE", "GRPC", "SERVICES", "VERSION", "LABELS")
+	for _, node := range nodes {
+		t.append(node.ID, node.Name, node.State, node.Entrance, node.GRPC.Endpoint, len(node.GRPC.Services), node.GitVersion, cluster.Selector(node.Labels))
+	}
+	return t.print()
+}
+
+func listServices(_ context.Context, r *cluster.Registry, _ []string) error {
+	type service struct {
+		NodeID   ulid.ULID `json:"node_id"`
+		NodeName string    `json:"node_name"`
+		cluster.GRPCServiceDesc
+	}
+
+	services := []service{}
+	for _, node := range allNodes(r) {
+		for _, desc := range node.GRPC.Services {
+			services = append(services, service{
+				NodeID:          node.ID,
+				NodeName:        node.Name,
+				GRPCServiceDesc: desc,
+			})
+		}
+	}
+
+	t := newTable(services, "NODE", "CODE", "NAME", "PUBLIC", "BALANCER", "STATEFUL", "ALLOCATION")
+	for _, s := range services {
+		t.append(s.NodeName, s.Code, s.Name, s.Public, s.Balancer, s.Stateful, s.Allocation)
+	}
+	return t.print()
+}
+
+// dumpResolver 解析器数据结构不固定，总是以json格式输出
+func dumpResolver(_ context.Context, r *cluster.Registry, _ []string) error {
+	return printJSON(r.DumpGRPCResolver())
+}
+
+func changeState(ctx context.Context, r *cluster.Registry, args []string) error {
+	node, err := findNode(r, args[0])
+	if err != nil {
+		return err
+	}
+
+	state := cluster.NodeState(args[1])
+	switch state {
+	case cluster.NodeOK, cluster.NodeLazy, cluster.NodeDown:
+	default:
+		return fmt.Errorf("invalid state: %s", state)
+	}
+
+	client, err := r.GetNodeClient(node.ID)
+	if err != nil {
+		return fmt.Errorf("get node client, %w", err)
+	}
+
+	if _, err := client.ChangeState(ctx, &nh.ChangeStateRequest{State: string(state)}); err != nil {
+		return fmt.Errorf("change state, %w", err)
+	}
+
+	return printResult(map[string]any{
+		"node":  node.ID,
+		"name":  node.Name,
+		"state": state,
+	})
+}
+
+func shutdownNode(ctx context.Context, r *cluster.Registry, args []string) error {
+	node, err := findNode(r, args[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := r.GetNodeClient(node.ID)
+	if err != nil {
+		return fmt.Errorf("get node client, %w", err)
+	}
+
+	if _, err := client.Shutdown(ctx, &emptypb.Empty{}); err != nil {
+		return fmt.Errorf("shutdown, %w", err)
+	}
+
+	return printResult(map[string]any{
+		"node":     node.ID,
+		"name":     node.Name,
+		"shutdown": true,
+	})
+}
+
+type gatewayResult struct {
+	GatewayID   ulid.ULID `json:"gateway_id"`
+	GatewayName string    `json:"gateway_name"`
+	Value       any       `json:"value"`
+}
+
+func countSessions(ctx context.Context, r *cluster.Registry, args []string) error {
+	results, err := eachGateway(r, optionalArg(args, 0), func(client nh.GatewayClient) (any, error) {
+		resp, err := client.SessionCount(ctx, &emptypb.Empty{})
+		return resp.GetCount(), err
+	})
+	if err != nil {
+		return err
+	}
+
+	return printGatewayResults(results, "SESSIONS")
+}
+
+func kickSession(ctx context.Context, r *cluster.Registry, args []string) error {
+	results, err := eachGateway(r, optionalArg(args, 1), func(client nh.GatewayClient) (any, error) {
+		resp, err := client.CloseSession(ctx, &nh.CloseSessionRequest{SessionId: args[0]})
+		return resp.GetSuccess(), err
+	})
+	if err != nil {
+		return err
+	}
+
+	return printGatewayResults(results, "CLOSED")
+}
+
+func editRoute(ctx context.Context, r *cluster.Registry, args []string) error {
+	var (
+		gateway string
+		call    func(client nh.GatewayClient) (any, error)
+	)
+
+	switch op, args := args[0], args[1:]; op {
+	case "get":
+		gateway = optionalArg(args, 1)
+		call = func(client nh.GatewayClient) (any, error) {
+			resp, err := client.GetServiceRoutes(ctx, &nh.GetServiceRoutesRequest{SessionId: args[0]})
+			return resp.GetRoutes(), err
+		}
+	case "set":
+		if len(args) < 3 {
+			return errors.New("usage: route set <session> <service> <node> [gateway]")
+		}
+
+		serviceCode, err := parseServiceCode(args[1])
+		if err != nil {
+			return err
+		}
+
+		node, err := findNode(r, args[2])
+		if err != nil {
+			return err
+		}
+
+		gateway = optionalArg(args, 3)
+		call = func(client nh.GatewayClient) (any, error) {
+			_, err := client.SetServiceRoute(ctx, &nh.SetServiceRouteRequest{
+				SessionId:   args[0],
+				ServiceCode: serviceCode,
+				NodeId:      node.ID.String(),
+			})
+			return err == nil, err
+		}
+	case "remove":
+		if len(args) < 2 {
+			return errors.New("usage: route remove <session> <service> [gateway]")
+		}
+
+		serviceCode, err := parseServiceCode(args[1])
+		if err != nil {
+			return err
+		}
+
+		gateway = optionalArg(args, 2)
+		call = func(client nh.GatewayClient) (any, error) {
+			_, err := client.RemoveServiceRoute(ctx, &nh.RemoveServiceRouteRequest{
+				SessionId:   args[0],
+				ServiceCode: serviceCode,
+			})
+			return err == nil, err
+		}
+	case "replace":
+		if len(args) < 2 {
+			return errors.New("usage: route replace <old node> <new node> [gateway]")
+		}
+
+		// 旧节点可能已经下线，允许直接使用节点ID
+		oldID, err := ulid.Parse(args[0])
+		if err != nil {
+			oldNode, err := findNode(r, args[0])
+			if err != nil {
+				return err
+			}
+			oldID = oldNode.ID
+		}
+
+		newNode, err := findNode(r, args[1])
+		if err != nil {
+			return err
+		}
+
+		gateway = optionalArg(args, 2)
+		call = func(client nh.GatewayClient) (any, error) {
+			_, err := client.ReplaceServiceRoute(ctx, &nh.ReplaceServiceRouteRequest{
+				OldNodeId: oldID.String(),
+				NewNodeId: newNode.ID.String(),
+			})
+			return err == nil, err
+		}
+	default:
+		return fmt.Errorf("unknown route operation: %s", op)
+	}
+
+	results, err := eachGateway(r, gateway, call)
+	if err != nil {
+		return err
+	}
+
+	if args[0] != "get" {
+		return printGatewayResults(results, "OK")
+	} else if config.Output == "json" {
+		return printJSON(results)
+	}
+
+	t := newTable(results, "GATEWAY", "SERVICE", "NODE", "NODE NAME")
+	for _, result := range results {
+		routes, _ := result.Value.(map[int32]string)
+
+		codes := make([]int32, 0, len(routes))
+		for code := range routes {
+			codes = append(codes, code)
+		}
+		sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+		for _, code := range codes {
+			var name string
+			if node, err := findNode(r, routes[code]); err == nil {
+				name = node.Name
+			}
+			t.append(result.GatewayName, code, routes[code], name)
+		}
+	}
+	return t.print()
+}
+
+func publishMulticast(ctx context.Context, _ *cluster.Registry, args []string) error {
+	var (
+		to          string
+		serviceCode int
+		code        int
+		data        string
+	)
+
+	fs := flag.NewFlagSet("multicast", flag.ContinueOnError)
+	fs.StringVar(&to, "to", "", "receiver session ids, comma separated")
+	fs.IntVar(&serviceCode, "service", 0, "reply service code")
+	fs.IntVar(&code, "code", 0, "reply code")
+	fs.StringVar(&data, "data", "", "base64 encoded reply data")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if to == "" {
+		return errors.New("receiver is required")
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return fmt.Errorf("decode data, %w", err)
+	}
+
+	var bus *multicast.Bus
+	switch {
+	case config.Nats != "":
+		conn, err := nats.Connect(config.Nats)
+		if err != nil {
+			return fmt.Errorf("connect nats, %w", err)
+		}
+		defer conn.Close()
+
+		bus = multicast.NewNatsBus(conn, multicast.WithChannelName(config.Channel))
+	case config.Redis != "":
+		client := redis.NewClient(&redis.Options{Addr: config.Redis})
+		defer client.Close()
+
+		bus = multicast.NewRedisBus(client, multicast.WithChannelName(config.Channel))
+	default:
+		return errors.New("either -nats or -redis is required")
+	}
+
+	receivers := strings.Split(to, ",")
+	if err := bus.Publish(ctx, &nh.Multicast{
+		Receiver: receivers,
+		Time:     timestamppb.Now(),
+		Content: &nh.Reply{
+			ServiceCode: int32(serviceCode),
+			Code:        int32(code),
+			Data:        payload,
+		},
+	}); err != nil {
+		return fmt.Errorf("publish, %w", err)
+	}
+
+	return printResult(map[string]any{
+		"receivers": receivers,
+		"service":   serviceCode,
+		"code":      code,
+		"size":      len(payload),
+	})
+}
+
+// allNodes 按名称排序的所有节点
+func allNodes(r *cluster.Registry) []cluster.NodeEntry {
+	nodes := []cluster.NodeEntry{}
+	r.ForeachNodes(func(entry cluster.NodeEntry) bool {
+		nodes = append(nodes, entry)
+		return true
+	})
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Name != nodes[j].Name {
+			return nodes[i].Name < nodes[j].Name
+		}
+		return nodes[i].ID.Compare(nodes[j].ID) < 0
+	})
+	return nodes
+}
+
+// editPolicy 查看、设置及删除服务的灰度流量策略
+func editPolicy(ctx context.Context, r *cluster.Registry, args []string) error {
+	switch op, args := args[0], args[1:]; op {
+	case "list":
+		policies := r.TrafficPolicies()
+
+		t := newTable(policies, "SERVICE", "VERSION", "PERCENT", "USERS", "USER KEY")
+		for _, p := range policies {
+			t.append(p.ServiceCode, p.Version, p.Percent, strings.Join(p.Users, ","), p.UserKey)
+		}
+		return t.print()
+	case "set":
+		var (
+			policy      cluster.TrafficPolicy
+			serviceCode int
+			users       string
+		)
+
+		fs := flag.NewFlagSet("policy set", flag.ContinueOnError)
+		fs.IntVar(&serviceCode, "service", 0, "service code")
+		fs.StringVar(&policy.Version, "version", "", "canary git version")
+		fs.IntVar(&policy.Percent, "percent", 0, "percent of users routed to canary version, 0-100")
+		fs.StringVar(&users, "users", "", "whitelist user ids, comma separated")
+		fs.StringVar(&policy.UserKey, "user-key", "", "session metadata key of user id, use session id if empty")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		policy.ServiceCode = int32(serviceCode)
+		if users != "" {
+			policy.Users = strings.Split(users, ",")
+		}
+
+		if err := r.SetTrafficPolicy(ctx, policy); err != nil {
+			return fmt.Errorf("set traffic policy, %w", err)
+		}
+		return printResult(map[string]any{
+			"service": policy.ServiceCode,
+			"version": policy.Version,
+			"percent": policy.Percent,
+		})
+	case "remove":
+		if len(args) < 1 {
+			return errors.New("usage: policy remove <service>")
+		}
+
+		serviceCode, err := parseServiceCode(args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := r.RemoveTrafficPolicy(ctx, serviceCode); err != nil {
+			return fmt.Errorf("remove traffic policy, %w", err)
+		}
+		return printResult(map[string]any{
+			"service": serviceCode,
+			"removed": true,
+		})
+	default:
+		return fmt.Errorf("unknown policy operation: %s", op)
+	}
+}
+
+// findNode 根据节点ID或者名称查找节点，名称必须唯一
+func findNode(r *cluster.Registry, idOrName string) (cluster.NodeEntry, error) {
+	id, idErr := ulid.Parse(idOrName)
+
+	var found []cluster.NodeEntry
+	r.ForeachNodes(func(entry cluster.NodeEntry) bool {
+		if (idErr == nil && entry.ID == id) || entry.Name == idOrName {
+			found = append(found, entry)
+		}
+		return true
+	})
+
+	switch len(found) {
+	case 0:
+		return cluster.NodeEntry{}, fmt.Errorf("node not found: %s", idOrName)
+	case 1:
+		return found[0], nil
+	default:
+		return cluster.NodeEntry{}, fmt.Errorf("node name is ambiguous, use node id instead: %s", idOrName)
+	}
+}
+
+// eachGateway 在指定网关或者所有网关上执行操作
+func eachGateway(r *cluster.Registry, gateway string, fn func(client nh.GatewayClient) (any, error)) ([]gatewayResult, error) {
+	var gateways []cluster.NodeEntry
+	if gateway != "" {
+		node, err := findNode(r, gateway)
+		if err != nil {
+			return nil, err
+		}
+		gateways = append(gateways, node)
+	} else {
+		for _, node := range allNodes(r) {
+			if isGateway(node) {
+				gateways = append(gateways, node)
+			}
+		}
+
+		if len(gateways) == 0 {
+			return nil, errors.New("no gateway found")
+		}
+	}
+
+	results := make([]gatewayResult, 0, len(gateways))
+	for _, node := range gateways {
+		client, err := r.GetGatewayClient(node.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get gateway client, %s, %w", node.Name, err)
+		}
+
+		value, err := fn(client)
+		if err != nil {
+			return nil, fmt.Errorf("gateway %s, %w", node.Name, err)
+		}
+
+		results = append(results, gatewayResult{
+			GatewayID:   node.ID,
+			GatewayName: node.Name,
+			Value:       value,
+		})
+	}
+	return results, nil
+}
+
+func isGateway(node cluster.NodeEntry) bool {
+	for _, desc := range node.GRPC.Services {
+		if desc.Code == nh.GatewayServiceCode {
+			return true
+		}
+	}
+	return false
+}
+
+func printGatewayResults(results []gatewayResult, valueName string) error {
+	t := newTable(results, "GATEWAY ID", "GATEWAY", valueName)
+	for _, result := range results {
+		t.append(result.GatewayID, result.GatewayName, result.Value)
+	}
+	return t.print()
+}
+
+func parseServiceCode(s string) (int32, error) {
+	v, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid service code: %s", s)
+	}
+	return int32(v), nil
+}
+
+func optionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
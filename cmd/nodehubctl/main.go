@@ -0,0 +1,201 @@
+// nodehubctl 集群管理命令行工具
+//
+// 从etcd读取服务注册表，通过内置的Node及Gateway管理接口操作集群节点
+//
+// Example:
+//
+//	nodehubctl -etcd 127.0.0.1:2379 nodes
+//	nodehubctl -o json services
+//	nodehubctl state gateway-1 lazy
+//	nodehubctl route get user-1
+//	nodehubctl policy set -service 1 -version v1.2.0 -percent 10
+//	nodehubctl -nats nats://127.0.0.1:4222 multicast -to user-1,user-2 -service 1 -code 100 -data CgVoZWxsbw==
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joyparty/nodehub/cluster"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+var config = struct {
+	Etcd    string
+	Prefix  string
+	Output  string
+	Timeout time.Duration
+
+	// 消息队列，用于发布multicast消息
+	Nats    string
+	Redis   string
+	Channel string
+}{}
+
+func init() {
+	flag.StringVar(&config.Etcd, "etcd", "127.0.0.1:2379", "etcd endpoints, comma separated")
+	flag.StringVar(&config.Prefix, "prefix", "/nodehub/node", "registry key prefix")
+	flag.StringVar(&config.Output, "o", "table", "output format, table or json")
+	flag.DurationVar(&config.Timeout, "timeout", 5*time.Second, "command timeout")
+	flag.StringVar(&config.Nats, "nats", "", "nats url, used by multicast command")
+	flag.StringVar(&config.Redis, "redis", "", "redis address, used by multicast command")
+	flag.StringVar(&config.Channel, "channel", "nodehub:multicast", "multicast channel name")
+
+	flag.Usage = usage
+}
+
+// command 子命令
+type command struct {
+	args string
+	desc string
+	// 最少参数数量
+	minArgs int
+	// 不需要读取服务注册表
+	noRegistry bool
+	run        func(ctx context.Context, r *cluster.Registry, args []string) error
+}
+
+var commands = map[string]*command{
+	"nodes": {
+		desc: "list all nodes",
+		run:  listNodes,
+	},
+	"services": {
+		desc: "list grpc services of all nodes",
+		run:  listServices,
+	},
+	"resolver": {
+		desc: "dump grpc resolver data",
+		run:  dumpResolver,
+	},
+	"state": {
+		args:    "<node> <ok|lazy|down>",
+		desc:    "change node state",
+		minArgs: 2,
+		run:     changeState,
+	},
+	"shutdown": {
+		args:    "<node>",
+		desc:    "shutdown node",
+		minArgs: 1,
+		run:     shutdownNode,
+	},
+	"sessions": {
+		args: "[gateway]",
+		desc: "count sessions of gateways",
+		run:  countSessions,
+	},
+	"kick": {
+		args:    "<session> [gateway]",
+		desc:    "close session",
+		minArgs: 1,
+		run:     kickSession,
+	},
+	"route": {
+		args:    "get <session> | set <session> <service> <node> | remove <session> <service> | replace <old node> <new node> [gateway]",
+		desc:    "inspect and edit stateful routes, apply to all gateways unless gateway is specified",
+		minArgs: 2,
+		run:     editRoute,
+	},
+	"policy": {
+		args:    "list | set -service <code> -version <version> -percent <0-100> [-users <ids>] [-user-key <key>] | remove <service>",
+		desc:    "inspect and edit canary traffic policies",
+		minArgs: 1,
+		run:     editPolicy,
+	},
+	"multicast": {
+		args:       "-to <sessions> -service <code> -code <code> [-data <base64>]",
+		desc:       "publish multicast message to sessions",
+		noRegistry: true,
+		run:        publishMulticast,
+	},
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] <command> [args...]\n\nCommands:\n", os.Args[0])
+	for _, name := range sortedKeys(commands) {
+		cmd := commands[name]
+		fmt.Fprintf(out, "  %s %s\n    \t%s\n", name, cmd.args, cmd.desc)
+	}
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Parse()
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return fmt.Errorf("command is required")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		flag.Usage()
+		return fmt.Errorf("unknown command: %s", args[0])
+	} else if len(args)-1 < cmd.minArgs {
+		return fmt.Errorf("usage: %s %s", args[0], cmd.args)
+	}
+
+	switch config.Output {
+	case "table", "json":
+	default:
+		return fmt.Errorf("unsupported output format: %s", config.Output)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	if cmd.noRegistry {
+		return cmd.run(ctx, nil, args[1:])
+	}
+
+	registry, err := newRegistry()
+	if err != nil {
+		return err
+	}
+	defer registry.Close()
+
+	return cmd.run(ctx, registry, args[1:])
+}
+
+func newRegistry() (*cluster.Registry, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   strings.Split(config.Etcd, ","),
+		DialTimeout: config.Timeout,
+		Logger:      zap.NewNop(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect etcd, %w", err)
+	}
+
+	registry, err := cluster.NewRegistry(client, cluster.WithKeyPrefix(config.Prefix))
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("create registry, %w", err)
+	}
+	return registry, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// table 表格输出，json格式下输出rows对应的原始数据
+type table struct {
+	header []string
+	rows   [][]any
+	data   any
+}
+
+func newTable(data any, header ...string) *table {
+	return &table{
+		header: header,
+		data:   data,
+	}
+}
+
+func (t *table) append(values ...any) {
+	t.rows = append(t.rows, values)
+}
+
+func (t *table) print() error {
+	if config.Output == "json" {
+		return printJSON(t.data)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		values := make([]string, 0, len(row))
+		for _, v := range row {
+			values = append(values, fmt.Sprint(v))
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printResult 输出操作结果
+func printResult(v map[string]any) error {
+	if config.Output == "json" {
+		return printJSON(v)
+	}
+
+	t := newTable(v, "key", "value")
+	for _, k := range sortedKeys(v) {
+		t.append(k, v[k])
+	}
+	return t.print()
+}
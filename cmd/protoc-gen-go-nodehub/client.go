@@ -0,0 +1,86 @@
+package main
+
+import (
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+const (
+	clientPackage  = protogen.GoImportPath("github.com/joyparty/nodehub/component/gateway/client")
+	contextPackage = protogen.GoImportPath("context")
+	fmtPackage     = protogen.GoImportPath("fmt")
+
+	emptyMessage = "google.protobuf.Empty"
+)
+
+// genGatewayClients 生成网关客户端调用代码及推送消息处理器注册函数
+func genGatewayClients(file *protogen.File, g *protogen.GeneratedFile) bool {
+	if !config.GatewayClient {
+		return false
+	}
+
+	services := parseServices(file)
+	messages := parseMessages(file)
+
+	lo.ForEach(services, func(s Service, _ int) {
+		genGatewayClient(s, g)
+	})
+
+	lo.ForEach(messages, func(m Message, _ int) {
+		g.P()
+		g.P("// On", m.GoIdent.GoName, " 注册", m.GoIdent.GoName, "消息处理器")
+		g.P("func On", m.GoIdent.GoName, "(c *", clientPackage.Ident("Client"), ", handler func(requestID uint32, msg *", m.GoIdent, ")) {")
+		g.P("c.OnReceive(", m.ReplyService.Interface(), ",", m.ReplyCode.Interface(), ", handler)")
+		g.P("}")
+	})
+
+	return len(services) > 0 || len(messages) > 0
+}
+
+func genGatewayClient(s Service, g *protogen.GeneratedFile) {
+	clientName := s.GoName + "GatewayClient"
+	replyCodes := lo.SliceToMap(s.Methods, func(m Method) (*protogen.Method, any) {
+		return m.Method, m.ReplyCode.Interface()
+	})
+
+	g.P()
+	g.P("// ", clientName, " 通过网关调用", s.GoName, "服务")
+	g.P("type ", clientName, " struct {")
+	g.P("c *", clientPackage.Ident("Client"))
+	g.P("}")
+	g.P()
+	g.P("// New", clientName, " 构造函数")
+	g.P("func New", clientName, "(c *", clientPackage.Ident("Client"), ") *", clientName, " {")
+	g.P("return &", clientName, "{c: c}")
+	g.P("}")
+
+	for _, m := range s.Service.Methods {
+		// 网关只支持unary方法，没有配置reply_code的返回值也无法解码，google.protobuf.Empty除外
+		if m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer() {
+			continue
+		}
+		replyCode, ok := replyCodes[m]
+		if !ok && m.Output.Desc.FullName() != emptyMessage {
+			continue
+		}
+
+		g.P()
+		g.P("func (x *", clientName, ") ", m.GoName, "(ctx ", contextPackage.Ident("Context"), ", in *", m.Input.GoIdent,
+			", opts ...", clientPackage.Ident("CallOption"), ") (*", m.Output.GoIdent, ", error) {")
+		g.P("reply, err := x.c.InvokeReply(ctx, ", s.Code.Interface(), ", ", `"`, m.Desc.Name(), `"`, ", in, opts...)")
+		g.P("if err != nil { return nil, err }")
+		if ok {
+			g.P()
+			g.P("if reply.GetCode() != ", replyCode, " {")
+			g.P("return nil, ", fmtPackage.Ident("Errorf"), `("unexpected reply code %d, expect `, replyCode, `", reply.GetCode())`)
+			g.P("}")
+		}
+		g.P()
+		g.P("out := new(", m.Output.GoIdent, ")")
+		g.P("if err := ", protoPackage.Ident("Unmarshal"), "(reply.GetData(), out); err != nil {")
+		g.P("return nil, ", fmtPackage.Ident("Errorf"), `("unmarshal reply message, %w", err)`)
+		g.P("}")
+		g.P("return out, nil")
+		g.P("}")
+	}
+}
@@ -23,6 +23,16 @@ var (
 	config = struct {
 		// 是否生成返回值表，可用于客户端解码
 		ReplyMessages bool
+		// 是否生成网关客户端调用代码
+		GatewayClient bool
+		// 是否生成json格式的协议描述及typescript解码表，供其它语言的客户端使用
+		Manifest bool
+		// 是否输出公开服务内没有配置reply_code的方法
+		Warnings bool
+		// 是否生成服务注册函数
+		Register bool
+		// 是否生成主动下行消息的推送函数
+		Push bool
 	}{}
 
 	flags flag.FlagSet
@@ -30,6 +40,11 @@ var (
 
 func init() {
 	flags.BoolVar(&config.ReplyMessages, "replyMessages", false, "build table of reply messages")
+	flags.BoolVar(&config.GatewayClient, "gatewayClient", false, "build typed gateway clients and push message handlers")
+	flags.BoolVar(&config.Manifest, "manifest", false, "build json manifest and typescript decoder table")
+	flags.BoolVar(&config.Warnings, "warnings", false, "warn about methods of public services without reply_code")
+	flags.BoolVar(&config.Register, "register", false, "build service register functions with routing options")
+	flags.BoolVar(&config.Push, "push", false, "build push functions of reply messages")
 }
 
 func main() {
@@ -42,9 +57,14 @@ func main() {
 			gokit.Must(registerAllExtensions(extTypes, file.Desc))
 		}
 
+		if err := validate(gen); err != nil {
+			return err
+		}
+
 		for _, file := range gen.Files {
 			if file.Generate {
 				generateFile(gen, file)
+				genManifest(gen, file)
 			}
 		}
 		return nil
@@ -71,6 +91,9 @@ func generateFile(gen *protogen.Plugin, file *protogen.File) *protogen.Generated
 	ok = genReplyMessages(file, g) || ok
 	ok = genMethodReplyCodes(file, g) || ok
 	ok = genPackFunctions(file, g) || ok
+	ok = genPushFunctions(file, g) || ok
+	ok = genGatewayClients(file, g) || ok
+	ok = genRegisterFunctions(file, g) || ok
 	if !ok {
 		g.Skip()
 	}
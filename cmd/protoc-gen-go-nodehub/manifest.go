@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/joyparty/gokit"
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// manifest 与语言无关的协议描述，供其它语言的客户端生成代码
+type manifest struct {
+	Source   string            `json:"source"`
+	Package  string            `json:"package"`
+	Services []manifestService `json:"services"`
+	Messages []manifestMessage `json:"messages"`
+}
+
+type manifestService struct {
+	Name    string           `json:"name"`
+	Code    int32            `json:"code"`
+	Methods []manifestMethod `json:"methods"`
+}
+
+type manifestMethod struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Request  string `json:"request"`
+	Response string `json:"response"`
+	// 没有配置reply_code的方法为nil
+	ReplyCode       *int32 `json:"reply_code,omitempty"`
+	ServerStreaming bool   `json:"server_streaming,omitempty"`
+}
+
+// manifestMessage 主动下行消息
+type manifestMessage struct {
+	Name         string `json:"name"`
+	ReplyService int32  `json:"reply_service"`
+	ReplyCode    int32  `json:"reply_code"`
+}
+
+const tsDeclarations = `export interface NodehubMethod {
+  name: string;
+  path: string;
+  request: string;
+  response: string;
+  reply_code?: number;
+  server_streaming?: boolean;
+}
+
+export interface NodehubService {
+  name: string;
+  code: number;
+  methods: NodehubMethod[];
+}
+
+export interface NodehubMessage {
+  name: string;
+  reply_service: number;
+  reply_code: number;
+}
+
+export interface NodehubManifest {
+  source: string;
+  package: string;
+  services: NodehubService[];
+  messages: NodehubMessage[];
+}`
+
+// genManifest 生成json格式的协议描述，以及typescript类型声明和返回值解码表
+func genManifest(gen *protogen.Plugin, file *protogen.File) {
+	if !config.Manifest {
+		return
+	}
+
+	m := parseManifest(file)
+	if len(m.Services) == 0 && len(m.Messages) == 0 {
+		return
+	}
+
+	data := gokit.MustReturn(json.MarshalIndent(m, "", "  "))
+	g := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+"_nodehub.json", file.GoImportPath)
+	g.P(string(data))
+
+	// 返回值解码表，key为"service_code:reply_code"，value为消息类型全名
+	replyTypes := map[string]string{}
+	for _, s := range m.Services {
+		for _, method := range s.Methods {
+			if method.ReplyCode != nil {
+				replyTypes[fmt.Sprintf("%d:%d", s.Code, *method.ReplyCode)] = method.Response
+			}
+		}
+	}
+	for _, msg := range m.Messages {
+		replyTypes[fmt.Sprintf("%d:%d", msg.ReplyService, msg.ReplyCode)] = msg.Name
+	}
+
+	g = gen.NewGeneratedFile(file.GeneratedFilenamePrefix+"_nodehub.ts", file.GoImportPath)
+	g.P("// Code generated by protoc-gen-go-nodehub. DO NOT EDIT.")
+	g.P("// source: ", file.Desc.Path())
+	g.P()
+	g.P(tsDeclarations)
+	g.P()
+	g.P("export const manifest: NodehubManifest = ", string(data), ";")
+	g.P()
+	g.P("// replyTypes key为\"service_code:reply_code\"，value为消息类型全名")
+	g.P("export const replyTypes: { [key: string]: string } = ", string(gokit.MustReturn(json.MarshalIndent(replyTypes, "", "  "))), ";")
+	g.P()
+	g.P("export function replyTypeOf(serviceCode: number, code: number): string | undefined {")
+	g.P("  return replyTypes[serviceCode + \":\" + code];")
+	g.P("}")
+}
+
+func parseManifest(file *protogen.File) manifest {
+	m := manifest{
+		Source:   file.Desc.Path(),
+		Package:  string(file.Desc.Package()),
+		Services: []manifestService{},
+		Messages: []manifestMessage{},
+	}
+
+	for _, s := range parseServices(file) {
+		replyCodes := lo.SliceToMap(s.Methods, func(m Method) (*protogen.Method, int32) {
+			return m.Method, toInt32(m.ReplyCode)
+		})
+
+		ms := manifestService{
+			Name:    string(s.Desc.FullName()),
+			Code:    toInt32(s.Code),
+			Methods: []manifestMethod{},
+		}
+		for _, method := range s.Service.Methods {
+			// 网关不支持client streaming方法
+			if method.Desc.IsStreamingClient() {
+				continue
+			}
+
+			mm := manifestMethod{
+				Name:            string(method.Desc.Name()),
+				Path:            fmt.Sprintf("/%s/%s", s.Desc.FullName(), method.Desc.Name()),
+				Request:         string(method.Input.Desc.FullName()),
+				Response:        string(method.Output.Desc.FullName()),
+				ServerStreaming: method.Desc.IsStreamingServer(),
+			}
+			if code, ok := replyCodes[method]; ok {
+				mm.ReplyCode = &code
+			}
+			ms.Methods = append(ms.Methods, mm)
+		}
+		m.Services = append(m.Services, ms)
+	}
+
+	for _, msg := range parseMessages(file) {
+		m.Messages = append(m.Messages, manifestMessage{
+			Name:         string(msg.Desc.FullName()),
+			ReplyService: toInt32(msg.ReplyService),
+			ReplyCode:    toInt32(msg.ReplyCode),
+		})
+	}
+
+	return m
+}
+
+// toInt32 选项值可能是枚举或者整数
+func toInt32(v protoreflect.Value) int32 {
+	switch x := v.Interface().(type) {
+	case protoreflect.EnumNumber:
+		return int32(x)
+	case int32:
+		return x
+	case int64:
+		return int32(x)
+	case uint32:
+		return int32(x)
+	case uint64:
+		return int32(x)
+	default:
+		panic(fmt.Errorf("unsupported option value type %T", x))
+	}
+}
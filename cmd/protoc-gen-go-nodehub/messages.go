@@ -10,8 +10,9 @@ import (
 )
 
 const (
-	nhPackage    = protogen.GoImportPath("github.com/joyparty/nodehub/proto/nh")
-	protoPackage = protogen.GoImportPath("google.golang.org/protobuf/proto")
+	nhPackage        = protogen.GoImportPath("github.com/joyparty/nodehub/proto/nh")
+	protoPackage     = protogen.GoImportPath("google.golang.org/protobuf/proto")
+	multicastPackage = protogen.GoImportPath("github.com/joyparty/nodehub/multicast")
 )
 
 type Message struct {
@@ -40,6 +41,47 @@ func genPackFunctions(file *protogen.File, g *protogen.GeneratedFile) bool {
 	return len(messages) > 0
 }
 
+// genPushFunctions 生成主动下行消息的推送函数，服务代码及消息代码来自proto配置，避免手工打包时写错
+func genPushFunctions(file *protogen.File, g *protogen.GeneratedFile) bool {
+	if !config.Push {
+		return false
+	}
+
+	messages := parseMessages(file)
+
+	lo.ForEach(messages, func(m Message, _ int) {
+		g.P()
+		g.P("// Push", m.GoIdent, " 通过消息队列向多个会话推送", m.GoIdent, "消息")
+		g.P("func Push", m.GoIdent, "(ctx ", contextPackage.Ident("Context"), ", publisher ", multicastPackage.Ident("Publisher"), ", receivers []string, msg *", m.GoIdent, ") error {")
+		g.P("reply, err := Pack", m.GoIdent, "(msg)")
+		g.P("if err != nil {")
+		g.P("return ", fmtPackage.Ident("Errorf"), `("pack reply, %w", err)`)
+		g.P("}")
+		g.P("return publisher.Publish(ctx, ", nhPackage.Ident("NewMulticast"), "(receivers, reply))")
+		g.P("}")
+
+		g.P()
+		g.P("// Send", m.GoIdent, " 通过网关grpc接口向会话推送", m.GoIdent, "消息，会话不存在时返回false")
+		g.P("func Send", m.GoIdent, "(ctx ", contextPackage.Ident("Context"), ", gateway ", nhPackage.Ident("GatewayClient"), ", sessionID string, msg *", m.GoIdent, ") (bool, error) {")
+		g.P("reply, err := Pack", m.GoIdent, "(msg)")
+		g.P("if err != nil {")
+		g.P("return false, ", fmtPackage.Ident("Errorf"), `("pack reply, %w", err)`)
+		g.P("}")
+		g.P()
+		g.P("resp, err := gateway.SendReply(ctx, &", nhPackage.Ident("SendReplyRequest"), "{")
+		g.P("SessionId: sessionID,")
+		g.P("Reply: reply,")
+		g.P("})")
+		g.P("if err != nil {")
+		g.P("return false, err")
+		g.P("}")
+		g.P("return resp.GetSuccess(), nil")
+		g.P("}")
+	})
+
+	return len(messages) > 0
+}
+
 func genReplyMessages(file *protogen.File, g *protogen.GeneratedFile) bool {
 	if !config.ReplyMessages {
 		return false
@@ -70,28 +112,7 @@ func genReplyMessages(file *protogen.File, g *protogen.GeneratedFile) bool {
 func parseMessages(file *protogen.File) []Message {
 	return lo.Filter(
 		lo.Map(file.Messages, func(m *protogen.Message, _ int) Message {
-			options := m.Desc.Options().(*descriptorpb.MessageOptions)
-			if options == nil {
-				return Message{}
-			}
-
-			data := gokit.MustReturn(proto.Marshal(options))
-			options.Reset()
-			gokit.Must(proto.UnmarshalOptions{Resolver: extTypes}.Unmarshal(data, options))
-
-			var replyService, replyCode protoreflect.Value
-			options.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
-				if fd.IsExtension() {
-					switch fd.Name() {
-					case optionReplyService:
-						replyService = v
-					case optionReplyCode:
-						replyCode = v
-					}
-				}
-				return true
-			})
-
+			replyService, replyCode := getReplyOptions(m)
 			return Message{
 				Message:      m,
 				ReplyService: replyService,
@@ -103,3 +124,27 @@ func parseMessages(file *protogen.File) []Message {
 		},
 	)
 }
+
+func getReplyOptions(m *protogen.Message) (replyService, replyCode protoreflect.Value) {
+	options := m.Desc.Options().(*descriptorpb.MessageOptions)
+	if options == nil {
+		return
+	}
+
+	data := gokit.MustReturn(proto.Marshal(options))
+	options.Reset()
+	gokit.Must(proto.UnmarshalOptions{Resolver: extTypes}.Unmarshal(data, options))
+
+	options.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsExtension() {
+			switch fd.Name() {
+			case optionReplyService:
+				replyService = v
+			case optionReplyCode:
+				replyCode = v
+			}
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/joyparty/gokit"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	optionPublic     = protoreflect.Name("public")
+	optionStateful   = protoreflect.Name("stateful")
+	optionAllocation = protoreflect.Name("allocation")
+	optionBalancer   = protoreflect.Name("balancer")
+	optionWeight     = protoreflect.Name("weight")
+	optionTimeout    = protoreflect.Name("timeout")
+
+	rpcPackage  = protogen.GoImportPath("github.com/joyparty/nodehub/component/rpc")
+	timePackage = protogen.GoImportPath("time")
+)
+
+// genRegisterFunctions 生成服务注册函数，自动应用proto内配置的路由选项以及返回值代码
+func genRegisterFunctions(file *protogen.File, g *protogen.GeneratedFile) bool {
+	if !config.Register {
+		return false
+	}
+
+	services := parseServices(file)
+	for _, s := range services {
+		ext := getExtensions(s.Desc.Options())
+
+		g.P()
+		g.P("// Register", s.GoName, " 注册", s.GoName, "服务，proto内配置的路由选项及返回值代码会自动生效，opts可以覆盖proto内的配置")
+		g.P("func Register", s.GoName, "(gs *", rpcPackage.Ident("GRPCServer"), ", impl ", s.GoName, "Server, opts ...", rpcPackage.Ident("Option"), ") error {")
+		g.P("options := []", rpcPackage.Ident("Option"), "{")
+		if v, ok := ext[optionPublic]; ok && v.Bool() {
+			g.P(rpcPackage.Ident("WithPublic"), "(),")
+		}
+		if v, ok := ext[optionStateful]; ok && v.Bool() {
+			g.P(rpcPackage.Ident("WithStateful"), "(),")
+		}
+		if v, ok := ext[optionAllocation]; ok {
+			g.P(rpcPackage.Ident("WithAllocation"), "(", strconv.Quote(v.String()), "),")
+		}
+		if v, ok := ext[optionBalancer]; ok {
+			g.P(rpcPackage.Ident("WithBalancer"), "(", strconv.Quote(v.String()), "),")
+		}
+		if v, ok := ext[optionWeight]; ok {
+			g.P(rpcPackage.Ident("WithWeight"), "(", toInt32(v), "),")
+		}
+		for _, m := range s.Service.Methods {
+			if v, ok := getExtensions(m.Desc.Options())[optionTimeout]; ok {
+				timeout := gokit.MustReturn(time.ParseDuration(v.String()))
+				g.P(rpcPackage.Ident("WithMethodTimeout"), "(", strconv.Quote(string(m.Desc.Name())), ", ", durationExpr(g, timeout), "),")
+			}
+		}
+		g.P("}")
+		g.P()
+		g.P("if err := gs.RegisterService(", s.Code.Interface(), ", ", s.GoName, "_ServiceDesc, impl, append(options, opts...)...); err != nil {")
+		g.P("return err")
+		g.P("}")
+		g.P()
+		g.P("gs.AddReplyCodes(", s.GoName, "_MethodReplyCodes)")
+		g.P("return nil")
+		g.P("}")
+	}
+
+	return len(services) > 0
+}
+
+func durationExpr(g *protogen.GeneratedFile, d time.Duration) string {
+	switch {
+	case d%time.Second == 0:
+		return fmt.Sprintf("%d*%s", d/time.Second, g.QualifiedGoIdent(timePackage.Ident("Second")))
+	case d%time.Millisecond == 0:
+		return fmt.Sprintf("%d*%s", d/time.Millisecond, g.QualifiedGoIdent(timePackage.Ident("Millisecond")))
+	default:
+		return fmt.Sprintf("%s(%d)", g.QualifiedGoIdent(timePackage.Ident("Duration")), int64(d))
+	}
+}
+
+// validateRouting 检查服务及方法的路由选项
+func validateRouting(s Service) []error {
+	var errs []error
+	name := s.Desc.FullName()
+
+	ext := getExtensions(s.Desc.Options())
+	for _, optName := range []protoreflect.Name{optionPublic, optionStateful, optionAllocation, optionBalancer, optionWeight} {
+		v, ok := ext[optName]
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch optName {
+		case optionPublic, optionStateful:
+			if _, ok := v.Interface().(bool); !ok {
+				err = errors.New("must be bool")
+			}
+		case optionAllocation:
+			switch v.Interface() {
+			case "auto", "server", "client":
+			default:
+				err = errors.New("must be one of auto, server, client")
+			}
+		case optionBalancer:
+			if x, ok := v.Interface().(string); !ok || x == "" {
+				err = errors.New("must be non-empty string")
+			}
+		case optionWeight:
+			switch v.Interface().(type) {
+			case int32, int64, uint32, uint64:
+			default:
+				err = errors.New("must be integer")
+			}
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("service %s option %s %w", name, optName, err))
+		}
+	}
+
+	for _, m := range s.Service.Methods {
+		v, ok := getExtensions(m.Desc.Options())[optionTimeout]
+		if !ok {
+			continue
+		}
+
+		if x, ok := v.Interface().(string); !ok {
+			errs = append(errs, fmt.Errorf("method %s option timeout must be duration string", m.Desc.FullName()))
+		} else if d, err := time.ParseDuration(x); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("method %s option timeout %q is not a positive duration", m.Desc.FullName(), x))
+		}
+	}
+
+	return errs
+}
+
+// getExtensions 按照名称获取自定义选项的值
+func getExtensions(options proto.Message) map[protoreflect.Name]protoreflect.Value {
+	result := map[protoreflect.Name]protoreflect.Value{}
+	if options == nil || !options.ProtoReflect().IsValid() {
+		return result
+	}
+
+	options = proto.Clone(options)
+	data := gokit.MustReturn(proto.Marshal(options))
+	proto.Reset(options)
+	gokit.Must(proto.UnmarshalOptions{Resolver: extTypes}.Unmarshal(data, options))
+
+	options.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsExtension() {
+			result[fd.Name()] = v
+		}
+		return true
+	})
+	return result
+}
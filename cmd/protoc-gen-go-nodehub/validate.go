@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+type replyKey struct {
+	service int32
+	code    int32
+}
+
+type codeOwner struct {
+	name string
+	file string
+}
+
+func (o codeOwner) String() string {
+	return fmt.Sprintf("%s (%s)", o.name, o.file)
+}
+
+// validate 检查本次生成的所有文件，service_code以及(reply_service, reply_code)不允许重复
+//
+// 重复的代码在运行时才会表现为nh.RegisterReplyType覆盖已有的类型，或者网关把请求转发到错误的服务
+func validate(gen *protogen.Plugin) error {
+	var (
+		errs     []error
+		services = map[int32]codeOwner{}
+		replies  = map[replyKey]codeOwner{}
+	)
+
+	addReply := func(key replyKey, owner codeOwner, source string) {
+		if exist, ok := replies[key]; ok && exist.name != owner.name {
+			errs = append(errs, fmt.Errorf("%s: reply_service %d reply_code %d of %s conflicts with %s",
+				source, key.service, key.code, owner.name, exist))
+		} else if !ok {
+			replies[key] = owner
+		}
+	}
+
+	for _, file := range gen.Files {
+		if !file.Generate {
+			continue
+		}
+		path := file.Desc.Path()
+
+		for _, s := range parseServices(file) {
+			code := toInt32(s.Code)
+			owner := codeOwner{name: string(s.Desc.FullName()), file: path}
+			if exist, ok := services[code]; ok {
+				errs = append(errs, fmt.Errorf("%s: service_code %d of %s conflicts with %s", path, code, owner.name, exist))
+			} else {
+				services[code] = owner
+			}
+
+			for _, m := range s.Methods {
+				addReply(
+					replyKey{service: code, code: toInt32(m.ReplyCode)},
+					codeOwner{name: string(m.Output.Desc.FullName()), file: m.Output.Location.SourceFile},
+					fmt.Sprintf("%s: method %s", path, m.Desc.FullName()),
+				)
+			}
+
+			if config.Register {
+				for _, err := range validateRouting(s) {
+					errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				}
+			}
+
+			if config.Warnings && isPublicService(s) {
+				warnMissingReplyCodes(s)
+			}
+		}
+
+		for _, m := range file.Messages {
+			// 只配置了reply_code的消息是方法返回值，在上面按照所属服务检查
+			replyService, replyCode := getReplyOptions(m)
+			if !replyService.IsValid() {
+				continue
+			} else if !replyCode.IsValid() {
+				errs = append(errs, fmt.Errorf("%s: message %s has reply_service but no reply_code", path, m.Desc.FullName()))
+				continue
+			}
+
+			addReply(
+				replyKey{service: toInt32(replyService), code: toInt32(replyCode)},
+				codeOwner{name: string(m.Desc.FullName()), file: path},
+				path,
+			)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// warnMissingReplyCodes 返回值没有配置reply_code的方法，客户端无法识别返回值类型
+func warnMissingReplyCodes(s Service) {
+	methods := lo.SliceToMap(s.Methods, func(m Method) (*protogen.Method, struct{}) {
+		return m.Method, struct{}{}
+	})
+
+	for _, m := range s.Service.Methods {
+		if _, ok := methods[m]; ok || m.Output.Desc.FullName() == emptyMessage {
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "warning: %s: method %s returns %s without reply_code\n",
+			s.Location.SourceFile, m.Desc.FullName(), m.Output.Desc.FullName())
+	}
+}
+
+// isPublicService 服务是否配置了public选项，私有服务不会被客户端调用
+func isPublicService(s Service) bool {
+	v, ok := getExtensions(s.Desc.Options())[optionPublic]
+	if !ok {
+		return false
+	}
+	public, _ := v.Interface().(bool)
+	return public
+}
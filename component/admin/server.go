@@ -0,0 +1,381 @@
+// Package admin 管理后台http服务
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joyparty/nodehub/cluster"
+	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/proto/nh"
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// AuthFunc 鉴权函数，返回false时拒绝请求，需要自行写入http响应
+type AuthFunc func(w http.ResponseWriter, r *http.Request) bool
+
+// Server 管理后台，展示集群节点及网关会话，提供踢下线、节点状态切换等操作
+//
+// 可以部署在任意节点上，通过服务注册表找到所有网关节点
+type Server struct {
+	addr     string
+	registry *cluster.Registry
+	auth     AuthFunc
+
+	s *http.Server
+}
+
+// Option 管理后台选项
+type Option func(s *Server)
+
+// WithAuth 设置鉴权函数，默认只允许本机访问
+func WithAuth(fn AuthFunc) Option {
+	return func(s *Server) {
+		s.auth = fn
+	}
+}
+
+// NewServer 构造函数
+func NewServer(addr string, registry *cluster.Registry, opts ...Option) *Server {
+	s := &Server{
+		addr:     addr,
+		registry: registry,
+		auth:     LocalOnly,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Name implements nodehub.Component interface.
+func (s *Server) Name() string {
+	return "admin"
+}
+
+// Start implements nodehub.Component interface.
+func (s *Server) Start(ctx context.Context) error {
+	l, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("listen admin server, %w", err)
+	}
+
+	s.s = &http.Server{
+		Handler: s,
+	}
+
+	go func() {
+		if err := s.s.Serve(l); err != nil && err != http.ErrServerClosed {
+			logger.Error("start admin server", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Stop implements nodehub.Component interface.
+func (s *Server) Stop(ctx context.Context) {
+	_ = s.s.Shutdown(ctx)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.auth(w, r) {
+		return
+	}
+
+	type route struct {
+		method  string
+		path    string
+		handler func(w http.ResponseWriter, r *http.Request) error
+	}
+
+	routes := []route{
+		{http.MethodGet, "/", s.dashboard},
+		{http.MethodGet, "/api/nodes", s.listNodes},
+		{http.MethodGet, "/api/sessions", s.listSessions},
+		{http.MethodPost, "/api/kick", s.kickSession},
+		{http.MethodPost, "/api/state", s.changeState},
+		{http.MethodPost, "/api/drain", s.drainNode},
+	}
+
+	for _, rt := range routes {
+		if rt.path != r.URL.Path {
+			continue
+		} else if rt.method != r.Method {
+			w.Header().Set("Allow", rt.method)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := rt.handler(w, r); err != nil {
+			writeError(w, err)
+		}
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+func (s *Server) dashboard(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err := w.Write(dashboardHTML)
+	return err
+}
+
+type nodeInfo struct {
+	cluster.NodeEntry
+
+	Gateway  bool   `json:"gateway"`
+	Sessions *int32 `json:"sessions,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func (s *Server) listNodes(w http.ResponseWriter, r *http.Request) error {
+	nodes := []nodeInfo{}
+	s.registry.ForeachNodes(func(entry cluster.NodeEntry) bool {
+		nodes = append(nodes, nodeInfo{
+			NodeEntry: entry,
+			Gateway:   isGateway(entry),
+		})
+		return true
+	})
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Name != nodes[j].Name {
+			return nodes[i].Name < nodes[j].Name
+		}
+		return nodes[i].ID.Compare(nodes[j].ID) < 0
+	})
+
+	for i, node := range nodes {
+		if !node.Gateway {
+			continue
+		}
+
+		client, err := s.registry.GetGatewayClient(node.ID)
+		if err != nil {
+			nodes[i].Error = err.Error()
+			continue
+		}
+
+		resp, err := client.SessionCount(r.Context(), &emptypb.Empty{})
+		if err != nil {
+			nodes[i].Error = err.Error()
+			continue
+		}
+
+		count := resp.GetCount()
+		nodes[i].Sessions = &count
+	}
+
+	return writeJSON(w, nodes)
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) error {
+	client, err := s.gatewayClient(r.URL.Query().Get("gateway"))
+	if err != nil {
+		return err
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	resp, err := client.ListSessions(r.Context(), &nh.ListSessionsRequest{
+		Offset: int32(offset),
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("list sessions, %w", err)
+	}
+	return writeProto(w, resp)
+}
+
+func (s *Server) kickSession(w http.ResponseWriter, r *http.Request) error {
+	var req struct {
+		Gateway string `json:"gateway"`
+		Session string `json:"session"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+
+	client, err := s.gatewayClient(req.Gateway)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.CloseSession(r.Context(), &nh.CloseSessionRequest{SessionId: req.Session})
+	if err != nil {
+		return fmt.Errorf("close session, %w", err)
+	}
+	return writeProto(w, resp)
+}
+
+func (s *Server) changeState(w http.ResponseWriter, r *http.Request) error {
+	var req struct {
+		Node  string `json:"node"`
+		State string `json:"state"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+
+	switch cluster.NodeState(req.State) {
+	case cluster.NodeOK, cluster.NodeLazy, cluster.NodeDown:
+	default:
+		return badRequest(fmt.Errorf("invalid state: %s", req.State))
+	}
+
+	return s.setState(w, r, req.Node, cluster.NodeState(req.State))
+}
+
+// drainNode 节点不再接受新的请求，已有的会话及请求不受影响
+func (s *Server) drainNode(w http.ResponseWriter, r *http.Request) error {
+	var req struct {
+		Node string `json:"node"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+
+	return s.setState(w, r, req.Node, cluster.NodeLazy)
+}
+
+func (s *Server) setState(w http.ResponseWriter, r *http.Request, node string, state cluster.NodeState) error {
+	nodeID, err := ulid.Parse(node)
+	if err != nil {
+		return badRequest(fmt.Errorf("invalid node id, %w", err))
+	}
+
+	client, err := s.registry.GetNodeClient(nodeID)
+	if err != nil {
+		return fmt.Errorf("get node client, %w", err)
+	}
+
+	if _, err := client.ChangeState(r.Context(), &nh.ChangeStateRequest{State: string(state)}); err != nil {
+		return fmt.Errorf("change state, %w", err)
+	}
+
+	return writeJSON(w, map[string]any{
+		"node":  nodeID,
+		"state": state,
+	})
+}
+
+func (s *Server) gatewayClient(gateway string) (nh.GatewayClient, error) {
+	nodeID, err := ulid.Parse(gateway)
+	if err != nil {
+		return nil, badRequest(fmt.Errorf("invalid gateway id, %w", err))
+	}
+
+	client, err := s.registry.GetGatewayClient(nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("get gateway client, %w", err)
+	}
+	return client, nil
+}
+
+func isGateway(entry cluster.NodeEntry) bool {
+	for _, desc := range entry.GRPC.Services {
+		if desc.Code == nh.GatewayServiceCode {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalOnly 只允许本机访问
+func LocalOnly(w http.ResponseWriter, r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err == nil {
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+			return true
+		}
+	}
+
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	return false
+}
+
+// BasicAuth http basic认证
+func BasicAuth(username, password string) AuthFunc {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		if u, p, ok := r.BasicAuth(); ok &&
+			subtle.ConstantTimeCompare([]byte(u), []byte(username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1 {
+			return true
+		}
+
+		w.Header().Set("WWW-Authenticate", `Basic realm="nodehub admin"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return false
+	}
+}
+
+type badRequestError struct {
+	error
+}
+
+func badRequest(err error) error {
+	return badRequestError{err}
+}
+
+// decodeJSON 操作类接口只接受json请求，避免跨站表单提交
+func decodeJSON(r *http.Request, v any) error {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+		return badRequest(errors.New("content type must be application/json"))
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20)).Decode(v); err != nil {
+		return badRequest(fmt.Errorf("decode request, %w", err))
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(v)
+}
+
+func writeProto(w http.ResponseWriter, msg proto.Message) error {
+	data, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(data)
+	return err
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusBadGateway
+	if errors.As(err, &badRequestError{}) {
+		code = http.StatusBadRequest
+	} else if errors.Is(err, cluster.ErrNodeNotFoundOrDown) {
+		code = http.StatusNotFound
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"error": strings.TrimSpace(err.Error()),
+	})
+}
@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/joyparty/nodehub/proto/nh"
+	"google.golang.org/protobuf/proto"
+)
+
+// 会话被拒绝的原因，用于metrics统计
+const (
+	rejectMaxSessions  = "max_sessions"
+	rejectMaxPerIP     = "max_sessions_per_ip"
+	rejectInitTimeout  = "init_timeout"
+	rejectPreAuthBytes = "pre_auth_bytes"
+)
+
+var (
+	errMaxSessions      = errors.New("too many sessions")
+	errMaxSessionsPerIP = errors.New("too many sessions from the same ip")
+	errPreAuthBytes     = errors.New("pre-auth bytes exceeded")
+)
+
+// admission 连接准入控制，限制会话总数及每个IP的会话数量
+type admission struct {
+	maxSessions int
+	maxPerIP    int
+
+	mux   sync.Mutex
+	total int
+	perIP map[string]int
+}
+
+func newAdmission(maxSessions, maxPerIP int) *admission {
+	return &admission{
+		maxSessions: maxSessions,
+		maxPerIP:    maxPerIP,
+		perIP:       map[string]int{},
+	}
+}
+
+// acquire 占用名额，成功后需要调用release释放
+func (a *admission) acquire(remoteAddr string) (release func(), reason string, err error) {
+	ip := remoteIP(remoteAddr)
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	if a.maxSessions > 0 && a.total >= a.maxSessions {
+		return nil, rejectMaxSessions, errMaxSessions
+	} else if a.maxPerIP > 0 && a.perIP[ip] >= a.maxPerIP {
+		return nil, rejectMaxPerIP, errMaxSessionsPerIP
+	}
+
+	a.total++
+	a.perIP[ip]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.mux.Lock()
+			defer a.mux.Unlock()
+
+			a.total--
+			if a.perIP[ip]--; a.perIP[ip] <= 0 {
+				delete(a.perIP, ip)
+			}
+		})
+	}, "", nil
+}
+
+func remoteIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// budgetSession 限制会话初始化完成之前客户端上行的数据量
+type budgetSession struct {
+	Session
+	remain int
+}
+
+func (bs *budgetSession) Recv(req *nh.Request) error {
+	if err := bs.Session.Recv(req); err != nil {
+		return err
+	}
+
+	if bs.remain -= proto.Size(req); bs.remain < 0 {
+		return errPreAuthBytes
+	}
+	return nil
+}
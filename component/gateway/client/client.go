@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
@@ -8,12 +9,14 @@ import (
 	"net"
 	"net/url"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/joyparty/gokit"
 	"github.com/joyparty/nodehub/internal/codec"
+	"github.com/joyparty/nodehub/internal/kcp"
 	"github.com/joyparty/nodehub/logger"
 	"github.com/joyparty/nodehub/proto/nh"
 	"github.com/quic-go/quic-go"
@@ -29,6 +32,11 @@ type connection interface {
 	Close()
 }
 
+// unreliableSender 支持不可靠方式发送的连接
+type unreliableSender interface {
+	sendUnreliable(service int32, data []byte) error
+}
+
 type tcpConn struct {
 	conn net.Conn
 	done chan struct{}
@@ -99,6 +107,82 @@ func (tc *tcpConn) Close() {
 	_ = tc.conn.Close()
 }
 
+// KCPConfig KCP传输参数
+type KCPConfig = kcp.Config
+
+type kcpConn struct {
+	conn *kcp.Conn
+	done chan struct{}
+}
+
+func newKCPConn(addr string, config *KCPConfig) (*kcpConn, error) {
+	conn, err := kcp.Dial(addr, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kcpConn{
+		conn: conn,
+		done: make(chan struct{}),
+	}, nil
+}
+
+func (kc *kcpConn) send(service int32, data []byte) error {
+	return codec.SendBytes(data, func(data []byte) error {
+		_ = kc.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		_, err := kc.conn.Write(data)
+		return err
+	})
+}
+
+func (kc *kcpConn) ping() error {
+	return kc.send(0, nil)
+}
+
+func (kc *kcpConn) replyStream() <-chan *nh.Reply {
+	ch := make(chan *nh.Reply)
+
+	go func() {
+		defer close(ch)
+
+		msg := codec.GetMessage()
+		defer codec.PutMessage(msg)
+
+		for {
+			select {
+			case <-kc.done:
+				return
+			default:
+			}
+
+			if err := codec.ReadMessage(kc.conn, msg); err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					logger.Error("read kcp", "error", err)
+				}
+				return
+			} else if msg.Len() == 0 {
+				continue
+			}
+
+			reply := &nh.Reply{}
+			gokit.Must(proto.Unmarshal(msg.Bytes(), reply))
+
+			select {
+			case <-kc.done:
+				return
+			case ch <- reply:
+			}
+		}
+	}()
+
+	return ch
+}
+
+func (kc *kcpConn) Close() {
+	close(kc.done)
+	_ = kc.conn.Close()
+}
+
 type quicConn struct {
 	conn    quic.Connection
 	streams []quic.Stream
@@ -106,6 +190,13 @@ type quicConn struct {
 }
 
 func newQUICConn(addr string, tlsConfig *tls.Config, quicConfig *quic.Config) (*quicConn, error) {
+	if quicConfig == nil {
+		quicConfig = &quic.Config{}
+	} else {
+		quicConfig = quicConfig.Clone()
+	}
+	quicConfig.EnableDatagrams = true
+
 	conn, err := quic.DialAddr(context.Background(), addr, tlsConfig, quicConfig)
 	if err != nil {
 		return nil, err
@@ -138,6 +229,20 @@ func (qc *quicConn) send(service int32, data []byte) error {
 	})
 }
 
+// sendUnreliable 通过datagram发送，不支持datagram或者超过长度限制时使用stream发送
+func (qc *quicConn) sendUnreliable(service int32, data []byte) error {
+	if qc.conn.ConnectionState().SupportsDatagrams {
+		err := codec.SendBytes(data, qc.conn.SendDatagram)
+
+		var tooLargeErr *quic.DatagramTooLargeError
+		if !errors.As(err, &tooLargeErr) {
+			return err
+		}
+	}
+
+	return qc.send(service, data)
+}
+
 func (qc *quicConn) ping() error {
 	return qc.send(0, nil)
 }
@@ -145,8 +250,20 @@ func (qc *quicConn) ping() error {
 func (qc *quicConn) replyStream() <-chan *nh.Reply {
 	c := make(chan *nh.Reply)
 
+	// 所有读取goroutine都结束后才关闭channel
+	var wg sync.WaitGroup
+	defer func() {
+		go func() {
+			wg.Wait()
+			close(c)
+		}()
+	}()
+
 	for _, s := range qc.streams {
+		wg.Add(1)
 		go func(s quic.Stream) {
+			defer wg.Done()
+
 			msg := codec.GetMessage()
 			defer codec.PutMessage(msg)
 
@@ -158,7 +275,14 @@ func (qc *quicConn) replyStream() <-chan *nh.Reply {
 				}
 
 				if err := codec.ReadMessage(s, msg); err != nil {
-					logger.Error("read quic", "error", err)
+					select {
+					case <-qc.done:
+					default:
+						logger.Error("read quic", "error", err)
+					}
+
+					// 任意一个stream出错，都视为连接断开
+					_ = qc.conn.CloseWithError(0, "")
 					return
 				}
 
@@ -174,6 +298,41 @@ func (qc *quicConn) replyStream() <-chan *nh.Reply {
 		}(s)
 	}
 
+	if qc.conn.ConnectionState().SupportsDatagrams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			msg := codec.GetMessage()
+			defer codec.PutMessage(msg)
+
+			for {
+				data, err := qc.conn.ReceiveDatagram(qc.conn.Context())
+				if err != nil {
+					return
+				}
+
+				// datagram不可靠，损坏的数据包直接丢弃
+				if err := codec.ReadMessage(bytes.NewReader(data), msg); err != nil {
+					logger.Error("read quic datagram", "error", err)
+					continue
+				}
+
+				reply := &nh.Reply{}
+				if err := proto.Unmarshal(msg.Bytes(), reply); err != nil {
+					logger.Error("unmarshal quic datagram", "error", err)
+					continue
+				}
+
+				select {
+				case <-qc.done:
+					return
+				case c <- reply:
+				}
+			}
+		}()
+	}
+
 	return c
 }
 
@@ -259,40 +418,63 @@ func (wc *wsConn) Close() {
 // Client 网关客户端，用于测试及演示
 type Client struct {
 	idSeq *atomic.Uint32
-	conn  connection
+	opts  *options
+
+	// 网关入口，重连时轮流选择，next由mux保护
+	entrances []string
+	next      int
+
+	mux    sync.Mutex
+	cs     *connSession
+	closed bool
+	closeC chan struct{}
+	state  atomic.Int32
 
 	// serviceCode => messageType => handler
 	handlers       *gokit.MapOf[int32, *gokit.MapOf[int32, func(*nh.Reply)]]
 	defaultHandler func(*nh.Reply)
+
+	// requestID => handler，Invoke()及Stream()等待中的回复
+	pending *gokit.MapOf[uint32, func(*nh.Reply)]
 }
 
-// New 创建客户端
-func New(dialURL string) (*Client, error) {
-	l, err := url.Parse(dialURL)
-	if err != nil {
-		return nil, fmt.Errorf("parse dial url, %w", err)
+// connSession 一次网关连接
+type connSession struct {
+	conn connection
+	// 连接断开后关闭
+	done chan struct{}
+	// 是否已经登录成功，只有登录成功的连接断开后才会触发重连
+	connected bool
+	closeOnce sync.Once
+}
+
+func (cs *connSession) close() {
+	cs.closeOnce.Do(cs.conn.Close)
+}
+
+// New 创建客户端，支持tcp://、ws://、quic://、kcp://
+//
+// quic连接需要通过WithTLSConfig()设置tls配置
+func New(dialURL string, opts ...Option) (*Client, error) {
+	o := newOptions()
+	for _, opt := range opts {
+		opt(o)
 	}
 
-	var cc connection
-	switch l.Scheme {
-	case "tcp":
-		cc, err = newTCPConn(l.Host)
-		if err != nil {
-			return nil, fmt.Errorf("dial tcp, %w", err)
-		}
-	case "ws":
-		cc, err = newWSConn(dialURL)
-		if err != nil {
-			return nil, fmt.Errorf("dial websocket, %w", err)
+	entrances := append([]string{dialURL}, o.entrances...)
+	for _, entrance := range entrances {
+		if err := checkDialURL(entrance, o); err != nil {
+			return nil, err
 		}
-	default:
-		return nil, fmt.Errorf("unsupported scheme: %s", l.Scheme)
 	}
 
 	c := &Client{
-		conn:     cc,
-		idSeq:    &atomic.Uint32{},
-		handlers: gokit.NewMapOf[int32, *gokit.MapOf[int32, func(*nh.Reply)]](),
+		idSeq:     &atomic.Uint32{},
+		opts:      o,
+		entrances: entrances,
+		closeC:    make(chan struct{}),
+		handlers:  gokit.NewMapOf[int32, *gokit.MapOf[int32, func(*nh.Reply)]](),
+		pending:   gokit.NewMapOf[uint32, func(*nh.Reply)](),
 		defaultHandler: func(reply *nh.Reply) {
 			fmt.Printf("%s REPLY: requestID=%d service=%d code=%d\n",
 				time.Now().Format(time.RFC3339),
@@ -303,12 +485,15 @@ func New(dialURL string) (*Client, error) {
 		},
 	}
 
-	go c.run()
+	if err := c.connect(dialURL); err != nil {
+		c.Close()
+		return nil, err
+	}
 	return c, nil
 }
 
-// NewQUIC 创建QUIC客户端
-func NewQUIC(dialURL string, tlsConfig *tls.Config, quicConfig *quic.Config) (*Client, error) {
+// NewQUIC 创建QUIC客户端，会自动开启quicConfig.EnableDatagrams
+func NewQUIC(dialURL string, tlsConfig *tls.Config, quicConfig *quic.Config, opts ...Option) (*Client, error) {
 	l, err := url.Parse(dialURL)
 	if err != nil {
 		return nil, fmt.Errorf("parse dial url, %w", err)
@@ -316,27 +501,92 @@ func NewQUIC(dialURL string, tlsConfig *tls.Config, quicConfig *quic.Config) (*C
 		return nil, fmt.Errorf("unsupported scheme: %s", l.Scheme)
 	}
 
-	qc, err := newQUICConn(l.Host, tlsConfig, quicConfig)
+	return New(dialURL, append([]Option{WithTLSConfig(tlsConfig), WithQUICConfig(quicConfig)}, opts...)...)
+}
+
+// NewKCP 创建KCP客户端，config为nil时使用默认配置
+func NewKCP(dialURL string, config *KCPConfig, opts ...Option) (*Client, error) {
+	l, err := url.Parse(dialURL)
 	if err != nil {
-		return nil, fmt.Errorf("dial quic, %w", err)
+		return nil, fmt.Errorf("parse dial url, %w", err)
+	} else if l.Scheme != "kcp" {
+		return nil, fmt.Errorf("unsupported scheme: %s", l.Scheme)
 	}
 
-	c := &Client{
-		conn:     qc,
-		idSeq:    &atomic.Uint32{},
-		handlers: gokit.NewMapOf[int32, *gokit.MapOf[int32, func(*nh.Reply)]](),
-		defaultHandler: func(reply *nh.Reply) {
-			fmt.Printf("%s REPLY: requestID=%d service=%d code=%d\n",
-				time.Now().Format(time.RFC3339),
-				reply.GetRequestId(),
-				reply.GetServiceCode(),
-				reply.GetCode(),
-			)
-		},
+	return New(dialURL, append([]Option{WithKCPConfig(config)}, opts...)...)
+}
+
+func checkDialURL(dialURL string, opts *options) error {
+	l, err := url.Parse(dialURL)
+	if err != nil {
+		return fmt.Errorf("parse dial url, %w", err)
 	}
 
-	go c.run()
-	return c, nil
+	switch l.Scheme {
+	case "tcp", "ws", "kcp":
+		return nil
+	case "quic":
+		if opts.tlsConfig == nil {
+			return errors.New("quic client requires tls config")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported scheme: %s", l.Scheme)
+	}
+}
+
+func dial(dialURL string, opts *options) (connection, error) {
+	l, err := url.Parse(dialURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse dial url, %w", err)
+	}
+
+	switch l.Scheme {
+	case "tcp":
+		cc, err := newTCPConn(l.Host)
+		if err != nil {
+			return nil, fmt.Errorf("dial tcp, %w", err)
+		}
+		return cc, nil
+	case "ws":
+		cc, err := newWSConn(dialURL)
+		if err != nil {
+			return nil, fmt.Errorf("dial websocket, %w", err)
+		}
+		return cc, nil
+	case "kcp":
+		cc, err := newKCPConn(l.Host, opts.kcpConfig)
+		if err != nil {
+			return nil, fmt.Errorf("dial kcp, %w", err)
+		}
+		return cc, nil
+	case "quic":
+		cc, err := newQUICConn(l.Host, opts.tlsConfig, opts.quicConfig)
+		if err != nil {
+			return nil, fmt.Errorf("dial quic, %w", err)
+		}
+		return cc, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme: %s", l.Scheme)
+	}
+}
+
+// State 当前连接状态
+func (c *Client) State() State {
+	return State(c.state.Load())
+}
+
+func (c *Client) setState(state State) {
+	if prev := State(c.state.Swap(int32(state))); prev != state && c.opts.stateHandler != nil {
+		c.opts.stateHandler(state)
+	}
+}
+
+// current 当前连接
+func (c *Client) current() *connSession {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.cs
 }
 
 // SetDefaultHandler 设置默认消息处理器
@@ -346,19 +596,23 @@ func (c *Client) SetDefaultHandler(handler func(reply *nh.Reply)) {
 
 // Call 发起远程调用
 func (c *Client) Call(serviceCode int32, method string, arg proto.Message, options ...CallOption) error {
-	req, err := c.newRequest(arg, options...)
+	req, err := c.newRequest(serviceCode, method, arg, options...)
 	if err != nil {
 		return fmt.Errorf("build request message, %w", err)
 	}
-	req.ServiceCode = serviceCode
-	req.Method = method
+	return c.send(c.current(), req)
+}
 
+func (c *Client) send(cs *connSession, req *nh.Request) error {
 	data, err := proto.Marshal(req)
 	if err != nil {
 		return fmt.Errorf("marshal request message, %w", err)
 	}
 
-	return c.conn.send(serviceCode, data)
+	if us, ok := cs.conn.(unreliableSender); ok && req.GetUnreliable() {
+		return us.sendUnreliable(req.GetServiceCode(), data)
+	}
+	return cs.conn.send(req.GetServiceCode(), data)
 }
 
 // OnReceive 注册消息处理器
@@ -406,9 +660,11 @@ func (c *Client) OnReceive(serviceCode int32, messageType int32, handler any) {
 	})
 }
 
-func (c *Client) newRequest(msg proto.Message, options ...CallOption) (*nh.Request, error) {
+func (c *Client) newRequest(serviceCode int32, method string, msg proto.Message, options ...CallOption) (*nh.Request, error) {
 	req := &nh.Request{
-		Id: c.idSeq.Add(1),
+		Id:          c.idSeq.Add(1),
+		ServiceCode: serviceCode,
+		Method:      method,
 	}
 
 	for _, opt := range options {
@@ -424,49 +680,169 @@ func (c *Client) newRequest(msg proto.Message, options ...CallOption) (*nh.Reque
 	return req, nil
 }
 
-func (c *Client) run() {
+// connect 连接网关并执行登录
+func (c *Client) connect(dialURL string) error {
+	c.setState(StateConnecting)
+
+	cc, err := dial(dialURL, c.opts)
+	if err != nil {
+		return err
+	}
+
+	cs := &connSession{
+		conn: cc,
+		done: make(chan struct{}),
+	}
+
+	c.mux.Lock()
+	if c.closed {
+		c.mux.Unlock()
+		cc.Close()
+		return ErrClosed
+	}
+	c.cs = cs
+	c.mux.Unlock()
+
+	go c.serve(cs)
+
+	if hook := c.opts.loginHook; hook != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), c.opts.loginTimeout)
+		defer cancel()
+
+		if err := hook(ctx, c); err != nil {
+			cs.close()
+			return fmt.Errorf("login, %w", err)
+		}
+	}
+
+	// 与serve()结束时的检查互斥，避免登录期间连接断开后没有触发重连
+	c.mux.Lock()
+	select {
+	case <-cs.done:
+		c.mux.Unlock()
+		return ErrClosed
+	default:
+		cs.connected = true
+		c.mux.Unlock()
+	}
+
+	c.setState(StateConnected)
+	return nil
+}
+
+func (c *Client) serve(cs *connSession) {
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
 		defer ticker.Stop()
 
-		for range ticker.C {
-			if err := c.conn.ping(); err != nil {
-				logger.Error("ping gateway", "error", err)
+		for {
+			select {
+			case <-cs.done:
+				return
+			case <-ticker.C:
+				if err := cs.conn.ping(); err != nil {
+					logger.Error("ping gateway", "error", err)
+				}
 			}
 		}
 	}()
 
-	ch := c.conn.replyStream()
+	for reply := range cs.conn.replyStream() {
+		logger.Debug("receive reply",
+			"requestID", reply.GetRequestId(),
+			"fromService", reply.GetServiceCode(),
+			"code", reply.GetCode(),
+		)
 
-Loop:
-	for {
-		select {
-		case reply, ok := <-ch:
-			if !ok {
-				break Loop
-			}
-			logger.Debug("receive reply",
-				"requestID", reply.GetRequestId(),
-				"fromService", reply.GetServiceCode(),
-				"code", reply.GetCode(),
-			)
+		if handler, ok := c.pending.Load(reply.GetRequestId()); ok {
+			handler(reply)
+			continue
+		}
 
-			if handlers, ok := c.handlers.Load(reply.GetServiceCode()); ok {
-				if handler, ok := handlers.Load(reply.GetCode()); ok {
-					go handler(reply)
-					continue
-				}
+		if handlers, ok := c.handlers.Load(reply.GetServiceCode()); ok {
+			if handler, ok := handlers.Load(reply.GetCode()); ok {
+				go handler(reply)
+				continue
 			}
-			c.defaultHandler(reply)
 		}
+		c.defaultHandler(reply)
 	}
+	cs.close()
+
+	c.mux.Lock()
+	close(cs.done)
+	closed, reconnect := c.closed, cs.connected
+	c.mux.Unlock()
 
 	logger.Info("gateway connection closed")
+
+	if closed {
+		c.setState(StateClosed)
+	} else if reconnect {
+		// 登录阶段断开的连接由connect()的调用方处理
+		if c.opts.minBackoff <= 0 {
+			c.Close()
+			return
+		}
+
+		c.setState(StateDisconnected)
+		go c.reconnect()
+	}
+}
+
+// reconnect 以指数退避方式重连，直到成功或者客户端关闭
+func (c *Client) reconnect() {
+	backoff := c.opts.minBackoff
+
+	for {
+		select {
+		case <-c.closeC:
+			return
+		case <-time.After(backoff):
+		}
+
+		dialURL := c.nextEntrance()
+
+		err := c.connect(dialURL)
+		if err == nil {
+			logger.Info("gateway reconnected", "url", dialURL)
+			return
+		}
+
+		logger.Warn("reconnect gateway", "url", dialURL, "error", err, "backoff", backoff)
+		c.setState(StateDisconnected)
+
+		if backoff *= 2; backoff > c.opts.maxBackoff {
+			backoff = c.opts.maxBackoff
+		}
+	}
+}
+
+// nextEntrance 轮流选择网关入口
+func (c *Client) nextEntrance() string {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.next = (c.next + 1) % len(c.entrances)
+	return c.entrances[c.next]
 }
 
 // Close 关闭客户端
 func (c *Client) Close() {
-	c.conn.Close()
+	c.mux.Lock()
+	if c.closed {
+		c.mux.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.closeC)
+	cs := c.cs
+	c.mux.Unlock()
+
+	if cs != nil {
+		cs.close()
+	}
+	c.setState(StateClosed)
 }
 
 // MustClient 使用must方法处理错误的客户端
@@ -489,6 +865,22 @@ func WithNode(nodeID string) CallOption {
 	}
 }
 
+// WithUnreliable 以不可靠方式发送请求及接收回复，仅quic连接有效
+func WithUnreliable() CallOption {
+	return func(req *nh.Request) {
+		req.Unreliable = true
+	}
+}
+
+// WithTimeout 指定请求超时时间，网关以服务器端配置的超时时间为上限，精度为毫秒
+//
+// Invoke的ctx设置了deadline并且没有指定超时时间时，会自动使用ctx的剩余时间
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(req *nh.Request) {
+		req.Timeout = uint32(timeout.Milliseconds())
+	}
+}
+
 // WithNoReply 不需要回复
 func WithNoReply() CallOption {
 	return func(req *nh.Request) {
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReconnect(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		l := newTestListener(t)
+
+		stateC := make(chan State, 10)
+		c, err := New("tcp://"+l.Addr().String(), WithStateHandler(func(s State) { stateC <- s }))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		acceptAndClose(t, l)
+		waitState(t, stateC, StateClosed)
+	})
+
+	t.Run("rotate entrances", func(t *testing.T) {
+		l1, l2 := newTestListener(t), newTestListener(t)
+
+		stateC := make(chan State, 10)
+		c, err := New("tcp://"+l1.Addr().String(),
+			WithEntrances("tcp://"+l2.Addr().String()),
+			WithReconnect(10*time.Millisecond, 20*time.Millisecond),
+			WithStateHandler(func(s State) { stateC <- s }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		waitState(t, stateC, StateConnected)
+		acceptAndClose(t, l1)
+		waitState(t, stateC, StateDisconnected)
+
+		// 重连到备用入口
+		_ = l2.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+		conn, err := l2.Accept()
+		if err != nil {
+			t.Fatalf("reconnect, %v", err)
+		}
+		defer conn.Close()
+		waitState(t, stateC, StateConnected)
+	})
+}
+
+func newTestListener(t *testing.T) net.Listener {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func acceptAndClose(t *testing.T, l net.Listener) {
+	t.Helper()
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func waitState(t *testing.T, stateC chan State, expected State) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case s := <-stateC:
+			if s == expected {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("wait state %s timeout", expected)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/proto/nh"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrClosed 连接已经断开，断线重连之前发起的调用不会收到回复
+var ErrClosed = errors.New("gateway connection closed")
+
+// ErrStreamOverflow Stream没有及时调用Recv，接收缓冲区已满
+var ErrStreamOverflow = errors.New("stream buffer overflow")
+
+// streamBufferSize Stream接收缓冲区大小
+const streamBufferSize = 64
+
+// Invoke 发起远程调用，并等待相同请求ID的回复，回复数据会解码到out
+//
+// 网关返回RPCError时，返回对应的gRPC status error；
+// ctx超时或取消时，返回codes.DeadlineExceeded或codes.Canceled的status error
+func (c *Client) Invoke(ctx context.Context, serviceCode int32, method string, in, out proto.Message, options ...CallOption) error {
+	reply, err := c.InvokeReply(ctx, serviceCode, method, in, options...)
+	if err != nil {
+		return err
+	}
+	return unmarshalReply(reply, out)
+}
+
+// InvokeReply 发起远程调用，返回未解码的回复，调用方可以根据回复的code自行解码
+//
+// 错误处理与Invoke相同
+func (c *Client) InvokeReply(ctx context.Context, serviceCode int32, method string, in proto.Message, options ...CallOption) (*nh.Reply, error) {
+	req, err := c.newRequest(serviceCode, method, in, options...)
+	if err != nil {
+		return nil, fmt.Errorf("build request message, %w", err)
+	} else if req.GetNoReply() {
+		return nil, errors.New("invoke with no reply option")
+	}
+
+	// 网关不需要在客户端放弃等待之后继续执行请求
+	if deadline, ok := ctx.Deadline(); ok && req.GetTimeout() == 0 {
+		if d := time.Until(deadline).Milliseconds(); d > 0 {
+			req.Timeout = uint32(d)
+		}
+	}
+
+	ch := make(chan *nh.Reply, 1)
+	c.pending.Store(req.GetId(), func(reply *nh.Reply) {
+		select {
+		case ch <- reply:
+		default:
+		}
+	})
+	defer c.pending.Delete(req.GetId())
+
+	cs := c.current()
+	if err := c.send(cs, req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case <-cs.done:
+		return nil, ErrClosed
+	case reply := <-ch:
+		if err := replyError(reply); err != nil {
+			return nil, err
+		}
+		return reply, nil
+	}
+}
+
+// Stream 发起远程调用，返回的Stream可以持续接收相同请求ID的回复，适用于server streaming方法
+func (c *Client) Stream(serviceCode int32, method string, in proto.Message, options ...CallOption) (*Stream, error) {
+	req, err := c.newRequest(serviceCode, method, in, options...)
+	if err != nil {
+		return nil, fmt.Errorf("build request message, %w", err)
+	} else if req.GetNoReply() {
+		return nil, errors.New("stream with no reply option")
+	}
+
+	s := &Stream{
+		c:    c,
+		cs:   c.current(),
+		id:   req.GetId(),
+		ch:   make(chan *nh.Reply, streamBufferSize),
+		done: make(chan struct{}),
+	}
+	c.pending.Store(s.id, s.deliver)
+
+	if err := c.send(s.cs, req); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Stream 相同请求ID的回复流
+//
+// 接收缓冲区满时Stream会被关闭，避免阻塞其它回复的处理
+type Stream struct {
+	c    *Client
+	cs   *connSession
+	id   uint32
+	ch   chan *nh.Reply
+	err  error
+	done chan struct{}
+	once sync.Once
+}
+
+// deliver 在连接的回复处理goroutine内调用，不能阻塞
+func (s *Stream) deliver(reply *nh.Reply) {
+	select {
+	case s.ch <- reply:
+	case <-s.done:
+	default:
+		logger.Error("stream buffer overflow", "requestID", s.id)
+		s.close(ErrStreamOverflow)
+	}
+}
+
+// Recv 接收下一个回复，回复数据会解码到out
+//
+// 网关返回RPCError时，返回对应的gRPC status error；Stream关闭后返回io.EOF，
+// 因为缓冲区溢出而关闭时，读完已缓冲的回复之后返回ErrStreamOverflow
+func (s *Stream) Recv(ctx context.Context, out proto.Message) error {
+	select {
+	case reply := <-s.ch:
+		return decodeReply(reply, out)
+	default:
+	}
+
+	select {
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-s.done:
+		select {
+		case reply := <-s.ch:
+			return decodeReply(reply, out)
+		default:
+		}
+
+		if s.err != nil {
+			return s.err
+		}
+		return io.EOF
+	case <-s.cs.done:
+		return ErrClosed
+	case reply := <-s.ch:
+		return decodeReply(reply, out)
+	}
+}
+
+// Close 停止接收回复
+func (s *Stream) Close() {
+	s.close(nil)
+}
+
+func (s *Stream) close(err error) {
+	s.once.Do(func() {
+		s.c.pending.Delete(s.id)
+		s.err = err
+		close(s.done)
+	})
+}
+
+func decodeReply(reply *nh.Reply, out proto.Message) error {
+	if err := replyError(reply); err != nil {
+		return err
+	}
+	return unmarshalReply(reply, out)
+}
+
+// replyError 把网关返回的RPCError转换为gRPC status error
+func replyError(reply *nh.Reply) error {
+	if reply.GetServiceCode() == 0 && reply.GetCode() == int32(nh.ReplyCode_RPC_ERROR) {
+		rpcErr := &nh.RPCError{}
+		if err := proto.Unmarshal(reply.GetData(), rpcErr); err != nil {
+			return fmt.Errorf("unmarshal rpc error, %w", err)
+		}
+		return status.ErrorProto(rpcErr.GetStatus())
+	}
+	return nil
+}
+
+func unmarshalReply(reply *nh.Reply, out proto.Message) error {
+	if out == nil {
+		return nil
+	} else if err := proto.Unmarshal(reply.GetData(), out); err != nil {
+		return fmt.Errorf("unmarshal reply message, %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/joyparty/gokit"
+	"github.com/joyparty/nodehub/proto/nh"
+)
+
+func TestStreamOverflow(t *testing.T) {
+	c := &Client{
+		pending: gokit.NewMapOf[uint32, func(*nh.Reply)](),
+	}
+
+	s := &Stream{
+		c:    c,
+		cs:   &connSession{done: make(chan struct{})},
+		id:   1,
+		ch:   make(chan *nh.Reply, streamBufferSize),
+		done: make(chan struct{}),
+	}
+	c.pending.Store(s.id, s.deliver)
+
+	// 模拟回复处理goroutine，溢出时不能阻塞
+	delivered := make(chan struct{})
+	go func() {
+		defer close(delivered)
+
+		for i := 0; i < streamBufferSize+10; i++ {
+			if handler, ok := c.pending.Load(s.id); ok {
+				handler(&nh.Reply{RequestId: s.id})
+			}
+		}
+	}()
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reply dispatcher blocked by stream")
+	}
+
+	if _, ok := c.pending.Load(s.id); ok {
+		t.Fatal("overflowed stream still pending")
+	}
+
+	ctx := context.Background()
+	for i := 0; i < streamBufferSize; i++ {
+		if err := s.Recv(ctx, nil); err != nil {
+			t.Fatalf("recv buffered reply %d, %v", i, err)
+		}
+	}
+
+	if err := s.Recv(ctx, nil); !errors.Is(err, ErrStreamOverflow) {
+		t.Fatalf("expected ErrStreamOverflow, got %v", err)
+	}
+}
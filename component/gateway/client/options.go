@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// State 客户端连接状态
+type State int32
+
+const (
+	// StateConnecting 正在连接及登录
+	StateConnecting State = iota
+	// StateConnected 已连接并登录成功
+	StateConnected
+	// StateDisconnected 连接断开，等待重连
+	StateDisconnected
+	// StateClosed 客户端已关闭
+	StateClosed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+type options struct {
+	tlsConfig  *tls.Config
+	quicConfig *quic.Config
+	kcpConfig  *KCPConfig
+
+	entrances  []string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	loginHook    func(ctx context.Context, c *Client) error
+	loginTimeout time.Duration
+	stateHandler func(State)
+}
+
+func newOptions() *options {
+	return &options{
+		loginTimeout: 10 * time.Second,
+	}
+}
+
+// Option 客户端选项
+type Option func(opts *options)
+
+// WithTLSConfig 设置tls配置，quic连接必须设置
+func WithTLSConfig(config *tls.Config) Option {
+	return func(opts *options) {
+		opts.tlsConfig = config
+	}
+}
+
+// WithQUICConfig 设置quic配置
+func WithQUICConfig(config *quic.Config) Option {
+	return func(opts *options) {
+		opts.quicConfig = config
+	}
+}
+
+// WithKCPConfig 设置kcp配置，nil使用默认配置
+func WithKCPConfig(config *KCPConfig) Option {
+	return func(opts *options) {
+		opts.kcpConfig = config
+	}
+}
+
+// WithEntrances 设置备用网关入口，断线重连时轮流选择
+func WithEntrances(dialURLs ...string) Option {
+	return func(opts *options) {
+		opts.entrances = append(opts.entrances, dialURLs...)
+	}
+}
+
+// WithReconnect 开启断线重连，每次重连失败后退避时间翻倍，直到max，例如1秒至30秒
+//
+// 默认不重连，连接断开后客户端直接关闭，min <= 0同样表示不重连
+func WithReconnect(min, max time.Duration) Option {
+	return func(opts *options) {
+		opts.minBackoff = min
+		opts.maxBackoff = max
+		if opts.maxBackoff < opts.minBackoff {
+			opts.maxBackoff = opts.minBackoff
+		}
+	}
+}
+
+// WithLoginHook 设置登录函数，每次连接成功后都会执行，返回错误视为连接失败
+//
+// 登录函数内可以使用Client.Invoke()发起调用，默认超时时间10秒
+func WithLoginHook(fn func(ctx context.Context, c *Client) error) Option {
+	return func(opts *options) {
+		opts.loginHook = fn
+	}
+}
+
+// WithLoginTimeout 设置登录函数的超时时间
+func WithLoginTimeout(timeout time.Duration) Option {
+	return func(opts *options) {
+		opts.loginTimeout = timeout
+	}
+}
+
+// WithStateHandler 设置连接状态变化的回调函数
+func WithStateHandler(fn func(State)) Option {
+	return func(opts *options) {
+		opts.stateHandler = fn
+	}
+}
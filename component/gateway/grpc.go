@@ -2,6 +2,8 @@ package gateway
 
 import (
 	"context"
+	"sort"
+	"strings"
 
 	"github.com/joyparty/nodehub/cluster"
 	"github.com/joyparty/nodehub/proto/nh"
@@ -9,6 +11,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var emptyReply = &emptypb.Empty{}
@@ -35,6 +38,47 @@ func (s *gwService) SessionCount(context.Context, *emptypb.Empty) (*nh.SessionCo
 	}, nil
 }
 
+// 会话列表
+func (s *gwService) ListSessions(ctx context.Context, req *nh.ListSessionsRequest) (*nh.ListSessionsResponse, error) {
+	sessions := []Session{}
+	s.sessionHub.Range(func(sess Session) bool {
+		sessions = append(sessions, sess)
+		return true
+	})
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ID() < sessions[j].ID()
+	})
+
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = 100
+	}
+	offset := min(max(int(req.GetOffset()), 0), len(sessions))
+	end := min(offset+limit, len(sessions))
+
+	resp := &nh.ListSessionsResponse{
+		Total:    int32(len(sessions)),
+		Sessions: make([]*nh.SessionInfo, 0, end-offset),
+	}
+	for _, sess := range sessions[offset:end] {
+		md := map[string]string{}
+		for k, v := range sess.MetadataCopy() {
+			md[k] = strings.Join(v, ",")
+		}
+
+		resp.Sessions = append(resp.Sessions, &nh.SessionInfo{
+			Id:         sess.ID(),
+			Type:       sess.Type(),
+			RemoteAddr: sess.RemoteAddr(),
+			LocalAddr:  sess.LocalAddr(),
+			LastRwTime: timestamppb.New(sess.LastRWTime()),
+			Metadata:   md,
+			Routes:     s.stateTable.Routes(sess.ID()),
+		})
+	}
+	return resp, nil
+}
+
 func (s *gwService) CloseSession(ctx context.Context, req *nh.CloseSessionRequest) (*nh.CloseSessionResponse, error) {
 	if sess, ok := s.sessionHub.Load(req.GetSessionId()); ok {
 		if err := sess.Close(); err != nil {
@@ -81,6 +125,12 @@ func (s *gwService) ReplaceServiceRoute(ctx context.Context, req *nh.ReplaceServ
 	return emptyReply, nil
 }
 
+func (s *gwService) GetServiceRoutes(ctx context.Context, req *nh.GetServiceRoutesRequest) (*nh.GetServiceRoutesResponse, error) {
+	return &nh.GetServiceRoutesResponse{
+		Routes: s.stateTable.Routes(req.GetSessionId()),
+	}, nil
+}
+
 func (s *gwService) SendReply(ctx context.Context, req *nh.SendReplyRequest) (*nh.SendReplyResponse, error) {
 	sess, ok := s.sessionHub.Load(req.GetSessionId())
 	if !ok {
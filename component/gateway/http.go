@@ -0,0 +1,650 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joyparty/gokit"
+	"github.com/joyparty/nodehub/cluster"
+	"github.com/joyparty/nodehub/internal/codec"
+	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
+	"github.com/joyparty/nodehub/proto/nh"
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	_ Session           = &httpSession{}
+	_ Session           = &sseSession{}
+	_ HTTPSession       = &httpSession{}
+	_ HTTPSession       = &sseSession{}
+	_ transientSession  = &httpSession{}
+	_ listenerSession   = &sseSession{}
+	_ rejectableSession = &httpSession{}
+	_ rejectableSession = &sseSession{}
+	_ Transporter       = &httpServer{}
+
+	// SSEHeartbeatInterval server-sent events心跳间隔
+	SSEHeartbeatInterval = 15 * time.Second
+)
+
+const (
+	contentTypeJSON     = "application/json"
+	contentTypeProtobuf = "application/x-protobuf"
+
+	// HeaderNodeID 请求头内指定节点ID，对应Request.node_id
+	HeaderNodeID = "X-Node-Id"
+)
+
+// HTTPSession http网关会话，可以在Initializer内获取原始http请求，用于鉴权
+type HTTPSession interface {
+	Session
+	Request() *http.Request
+}
+
+// httpServer http网关服务器
+//
+// 供浏览器及各种工具以普通http请求的方式访问服务，每个请求都会经过Initializer鉴权:
+//   - POST {path}/{service_code}/{method}，调用服务方法，请求内容为方法参数
+//   - GET {path}/events，以server-sent events的方式接收主动下行消息
+//
+// 请求的Content-Type为application/json时，请求内容及响应都使用json格式，需要配置JSONCodec；
+// 否则请求内容为方法参数protobuf message序列化之后的数据，响应为nh.Reply序列化之后的数据
+//
+// server-sent events下行的每条消息都是json格式的nh.Reply，鉴权失败时返回401或403(PermissionDenied)
+type httpServer struct {
+	url       *url.URL
+	listener  net.Listener
+	server    *http.Server
+	jsonCodec *JSONCodec
+}
+
+// NewHTTPServer 构造函数，jsonCodec为nil时不支持json格式请求
+func NewHTTPServer(listenAddr string, urlPath string, jsonCodec *JSONCodec) Transporter {
+	return &httpServer{
+		url: &url.URL{
+			Scheme: "http",
+			Host:   listenAddr,
+			Path:   urlPath,
+		},
+		jsonCodec: jsonCodec,
+	}
+}
+
+// BindHTTPServer 绑定http服务器
+func BindHTTPServer(listener net.Listener, urlPath string, jsonCodec *JSONCodec) Transporter {
+	return &httpServer{
+		listener: listener,
+		url: &url.URL{
+			Scheme: "http",
+			Host:   listener.Addr().String(),
+			Path:   urlPath,
+		},
+		jsonCodec: jsonCodec,
+	}
+}
+
+// CompleteNodeEntry 补全节点信息
+func (hs *httpServer) CompleteNodeEntry(entry *cluster.NodeEntry) {
+	entry.Entrance = hs.url.String()
+}
+
+func (hs *httpServer) Serve(ctx context.Context) (chan Session, error) {
+	ch := make(chan Session)
+
+	prefix := strings.TrimSuffix(hs.url.Path, "/")
+
+	handleEvents := func(w http.ResponseWriter, r *http.Request) {
+		sess, err := newSSESession(w, r, hs.jsonCodec)
+		if err != nil {
+			logger.Error("initialize session", "error", err, "remoteAddr", r.RemoteAddr)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer sess.finish()
+
+		if !hs.submit(ch, sess) {
+			return
+		}
+
+		select {
+		case <-sess.done:
+		case <-r.Context().Done():
+		}
+	}
+
+	handleCall := func(w http.ResponseWriter, r *http.Request, service, method string) {
+		req, isJSON, size, err := hs.parseRequest(w, r, service, method)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			} else if errors.Is(err, errUnsupportedMediaType) {
+				http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			} else {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+			return
+		}
+
+		sess := newHTTPSession(w, r, req, isJSON, hs.jsonCodec)
+		sess.size = size
+		defer sess.finish()
+
+		if !hs.submit(ch, sess) {
+			return
+		}
+
+		select {
+		case <-sess.done:
+		case <-r.Context().Done():
+		}
+	}
+
+	router := http.NewServeMux()
+	router.HandleFunc(prefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, prefix+"/"), "/")
+
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "events":
+			handleEvents(w, r)
+		case r.Method == http.MethodPost && len(parts) == 2:
+			handleCall(w, r, parts[0], parts[1])
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	hs.server = &http.Server{
+		Handler: router,
+	}
+
+	go func() {
+		defer close(ch)
+
+		var err error
+		if hs.listener != nil {
+			err = hs.server.Serve(hs.listener)
+		} else {
+			hs.server.Addr = hs.url.Host
+			err = hs.server.ListenAndServe()
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			logger.Error("start gateway", "error", err)
+			panic(fmt.Errorf("start gateway, %w", err))
+		}
+	}()
+
+	return ch, nil
+}
+
+func (hs *httpServer) submit(ch chan Session, sess Session) (ok bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			// 服务器已经关闭
+			ok = false
+		}
+	}()
+
+	ch <- sess
+	return true
+}
+
+var errUnsupportedMediaType = errors.New("unsupported media type")
+
+func (hs *httpServer) parseRequest(w http.ResponseWriter, r *http.Request, service, method string) (req *nh.Request, isJSON bool, size int, err error) {
+	serviceCode, err := strconv.ParseInt(service, 10, 32)
+	if err != nil {
+		return nil, false, 0, fmt.Errorf("invalid service code, %w", err)
+	}
+
+	switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType {
+	case contentTypeJSON:
+		if hs.jsonCodec == nil {
+			return nil, false, 0, errUnsupportedMediaType
+		}
+		isJSON = true
+	case contentTypeProtobuf, "application/octet-stream", "":
+	default:
+		return nil, false, 0, errUnsupportedMediaType
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(codec.MaxMessageSize)))
+	if err != nil {
+		return nil, false, 0, fmt.Errorf("read body, %w", err)
+	}
+
+	req = &nh.Request{
+		Id:          1,
+		ServiceCode: int32(serviceCode),
+		Method:      method,
+		NodeId:      r.Header.Get(HeaderNodeID),
+	}
+
+	if isJSON && len(data) > 0 {
+		if err := hs.jsonCodec.decodeData(req, data); err != nil {
+			return nil, false, 0, fmt.Errorf("decode json request, %w", err)
+		}
+	} else {
+		req.Data = data
+	}
+	return req, isJSON, len(data), nil
+}
+
+// Shutdown 停止http服务器
+func (hs *httpServer) Shutdown(ctx context.Context) error {
+	return hs.server.Shutdown(ctx)
+}
+
+// httpSession 单次http请求会话
+type httpSession struct {
+	id         string
+	md         metadata.MD
+	r          *http.Request
+	w          http.ResponseWriter
+	req        *nh.Request
+	isJSON     bool
+	jsonCodec  *JSONCodec
+	size       int // 请求body大小
+	lastRWTime gokit.ValueOf[time.Time]
+	metrics    *metrics.Metrics
+
+	mux      sync.Mutex
+	received bool
+	replied  bool
+	finished bool // handler已经返回，不能再写入响应
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newHTTPSession(w http.ResponseWriter, r *http.Request, req *nh.Request, isJSON bool, jsonCodec *JSONCodec) *httpSession {
+	hs := &httpSession{
+		id:         ulid.Make().String(),
+		md:         metadata.New(nil),
+		r:          r,
+		w:          w,
+		req:        req,
+		isJSON:     isJSON,
+		jsonCodec:  jsonCodec,
+		lastRWTime: gokit.NewValueOf[time.Time](),
+		done:       make(chan struct{}),
+	}
+	hs.lastRWTime.Store(time.Now())
+
+	return hs
+}
+
+func (hs *httpSession) transient() bool {
+	return true
+}
+
+func (hs *httpSession) Type() string {
+	return "http"
+}
+
+func (hs *httpSession) ID() string {
+	return hs.id
+}
+
+func (hs *httpSession) SetID(id string) {
+	hs.id = id
+}
+
+func (hs *httpSession) SetMetadata(md metadata.MD) {
+	hs.md = md
+}
+
+func (hs *httpSession) MetadataCopy() metadata.MD {
+	return hs.md.Copy()
+}
+
+func (hs *httpSession) setMetrics(m *metrics.Metrics) {
+	hs.metrics = m
+}
+
+// Request 原始http请求
+func (hs *httpSession) Request() *http.Request {
+	return hs.r
+}
+
+// Recv 每个会话只有一个请求
+func (hs *httpSession) Recv(req *nh.Request) error {
+	hs.mux.Lock()
+	defer hs.mux.Unlock()
+
+	if hs.received {
+		return io.EOF
+	}
+	hs.received = true
+	hs.metrics.IncrPayloadSize(hs.Type(), hs.size)
+
+	proto.Merge(req, hs.req)
+	return nil
+}
+
+func (hs *httpSession) Send(reply *nh.Reply) error {
+	hs.mux.Lock()
+	defer hs.mux.Unlock()
+
+	if hs.finished {
+		return net.ErrClosed
+	} else if hs.replied {
+		return errors.New("already replied")
+	}
+	hs.replied = true
+
+	var (
+		data        []byte
+		contentType = contentTypeProtobuf
+		err         error
+	)
+	if hs.isJSON {
+		contentType = contentTypeJSON
+		data, err = hs.jsonCodec.EncodeReply(reply)
+	} else {
+		data, err = proto.Marshal(reply)
+	}
+	if err != nil {
+		http.Error(hs.w, "internal server error", http.StatusInternalServerError)
+		return fmt.Errorf("marshal response, %w", err)
+	}
+
+	hs.w.Header().Set("Content-Type", contentType)
+	hs.w.WriteHeader(http.StatusOK)
+	_, err = hs.w.Write(data)
+	hs.lastRWTime.Store(time.Now())
+	return err
+}
+
+func (hs *httpSession) LocalAddr() string {
+	if addr, ok := hs.r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		return addr.String()
+	}
+	return ""
+}
+
+func (hs *httpSession) RemoteAddr() string {
+	return hs.r.RemoteAddr
+}
+
+func (hs *httpSession) LastRWTime() time.Time {
+	return hs.lastRWTime.Load()
+}
+
+// Close 没有下行任何响应时，返回204
+func (hs *httpSession) Close() error {
+	hs.closeOnce.Do(func() {
+		hs.mux.Lock()
+		if !hs.finished && !hs.replied {
+			hs.replied = true
+			hs.w.WriteHeader(http.StatusNoContent)
+		}
+		hs.mux.Unlock()
+
+		close(hs.done)
+	})
+	return nil
+}
+
+// reject 鉴权失败，返回401或403
+func (hs *httpSession) reject(err error) {
+	hs.mux.Lock()
+	defer hs.mux.Unlock()
+
+	if !hs.finished && !hs.replied {
+		hs.replied = true
+		code, text := rejectResponse(err)
+		http.Error(hs.w, text, code)
+	}
+}
+
+func (hs *httpSession) finish() {
+	hs.mux.Lock()
+	hs.finished = true
+	hs.mux.Unlock()
+}
+
+func (hs *httpSession) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", hs.id),
+		slog.String("type", "http"),
+		slog.String("addr", hs.RemoteAddr()),
+	)
+}
+
+// sseSession server-sent events会话，只用于接收下行消息
+type sseSession struct {
+	id         string
+	md         metadata.MD
+	r          *http.Request
+	w          http.ResponseWriter
+	flusher    http.Flusher
+	jsonCodec  *JSONCodec
+	lastRWTime gokit.ValueOf[time.Time]
+
+	writeMux  sync.Mutex
+	responded bool // 已经写入响应头
+	finished  bool // handler已经返回，不能再写入响应
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newSSESession(w http.ResponseWriter, r *http.Request, jsonCodec *JSONCodec) (*sseSession, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming unsupported")
+	}
+
+	ss := &sseSession{
+		id:         ulid.Make().String(),
+		md:         metadata.New(nil),
+		r:          r,
+		w:          w,
+		flusher:    flusher,
+		jsonCodec:  jsonCodec,
+		lastRWTime: gokit.NewValueOf[time.Time](),
+		done:       make(chan struct{}),
+	}
+	ss.lastRWTime.Store(time.Now())
+
+	return ss, nil
+}
+
+func (ss *sseSession) listener() bool {
+	return true
+}
+
+// accept 鉴权通过之后才写入响应头，开始下行消息
+func (ss *sseSession) accept() error {
+	ss.writeMux.Lock()
+	defer ss.writeMux.Unlock()
+
+	if ss.finished {
+		return net.ErrClosed
+	} else if ss.responded {
+		return errors.New("already responded")
+	}
+	ss.responded = true
+
+	ss.w.Header().Set("Content-Type", "text/event-stream")
+	ss.w.Header().Set("Cache-Control", "no-cache")
+	ss.w.WriteHeader(http.StatusOK)
+	ss.flusher.Flush()
+	ss.lastRWTime.Store(time.Now())
+
+	go ss.heartbeat()
+	return nil
+}
+
+// reject 鉴权失败，返回401或403
+func (ss *sseSession) reject(err error) {
+	ss.writeMux.Lock()
+	defer ss.writeMux.Unlock()
+
+	if !ss.finished && !ss.responded {
+		ss.responded = true
+		code, text := rejectResponse(err)
+		http.Error(ss.w, text, code)
+	}
+}
+
+func (ss *sseSession) Type() string {
+	return "sse"
+}
+
+func (ss *sseSession) ID() string {
+	return ss.id
+}
+
+func (ss *sseSession) SetID(id string) {
+	ss.id = id
+}
+
+func (ss *sseSession) SetMetadata(md metadata.MD) {
+	ss.md = md
+}
+
+func (ss *sseSession) MetadataCopy() metadata.MD {
+	return ss.md.Copy()
+}
+
+// Request 原始http请求
+func (ss *sseSession) Request() *http.Request {
+	return ss.r
+}
+
+// Recv server-sent events不会有上行请求，一直阻塞到连接断开
+func (ss *sseSession) Recv(req *nh.Request) error {
+	select {
+	case <-ss.done:
+	case <-ss.r.Context().Done():
+	}
+	return io.EOF
+}
+
+func (ss *sseSession) Send(reply *nh.Reply) error {
+	data, err := ss.jsonCodec.EncodeReply(reply)
+	if err != nil {
+		return fmt.Errorf("marshal response, %w", err)
+	}
+
+	return ss.write(fmt.Sprintf("data: %s\n\n", data))
+}
+
+// 定时发送注释行，避免连接被中间代理断开，同时更新活跃时间
+func (ss *sseSession) heartbeat() {
+	ticker := time.NewTicker(SSEHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ss.done:
+			return
+		case <-ss.r.Context().Done():
+			return
+		case <-ticker.C:
+			if err := ss.write(": ping\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (ss *sseSession) write(s string) error {
+	ss.writeMux.Lock()
+	defer ss.writeMux.Unlock()
+
+	if ss.finished {
+		return net.ErrClosed
+	} else if !ss.responded {
+		return errors.New("session not accepted")
+	}
+
+	if _, err := io.WriteString(ss.w, s); err != nil {
+		return err
+	}
+	ss.flusher.Flush()
+	ss.lastRWTime.Store(time.Now())
+	return nil
+}
+
+func (ss *sseSession) LocalAddr() string {
+	if addr, ok := ss.r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		return addr.String()
+	}
+	return ""
+}
+
+func (ss *sseSession) RemoteAddr() string {
+	return ss.r.RemoteAddr
+}
+
+func (ss *sseSession) LastRWTime() time.Time {
+	return ss.lastRWTime.Load()
+}
+
+// Close 没有写入任何响应时，返回503
+func (ss *sseSession) Close() error {
+	ss.closeOnce.Do(func() {
+		ss.writeMux.Lock()
+		if !ss.finished && !ss.responded {
+			ss.responded = true
+			ss.w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		ss.writeMux.Unlock()
+
+		close(ss.done)
+	})
+	return nil
+}
+
+func (ss *sseSession) finish() {
+	ss.writeMux.Lock()
+	ss.finished = true
+	ss.writeMux.Unlock()
+}
+
+func (ss *sseSession) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", ss.id),
+		slog.String("type", "sse"),
+		slog.String("addr", ss.RemoteAddr()),
+	)
+}
+
+// rejectResponse 鉴权失败时的状态码及错误描述，Initializer返回PermissionDenied时为403，否则为401
+//
+// 只有gRPC status错误会返回错误描述，其它错误以及unknown错误只返回状态码说明，避免泄露信息到客户端
+func rejectResponse(err error) (int, string) {
+	code := http.StatusUnauthorized
+	if status.Code(err) == codes.PermissionDenied {
+		code = http.StatusForbidden
+	}
+
+	// status.FromError()会把外层包装的错误描述也加入到Message内，所以直接取被包装的status
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		if s := se.GRPCStatus(); s.Code() != codes.Unknown && s.Message() != "" {
+			return code, s.Message()
+		}
+	}
+	return code, http.StatusText(code)
+}
@@ -0,0 +1,132 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joyparty/nodehub/proto/nh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSSESession(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := BindHTTPServer(listener, "/nodehub", nil)
+	sessC, err := server.Serve(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown(ctx)
+
+	url := "http://" + listener.Addr().String() + "/nodehub/events"
+
+	// 请求在独立的goroutine内发起，直到handler写入响应头才会返回
+	get := func() (chan *http.Response, Session) {
+		respC := make(chan *http.Response, 1)
+		go func() {
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Errorf("request events, %v", err)
+				close(respC)
+				return
+			}
+			respC <- resp
+		}()
+
+		select {
+		case sess := <-sessC:
+			return respC, sess
+		case <-ctx.Done():
+			t.Fatal("session not accepted")
+		}
+		return nil, nil
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		for _, c := range []struct {
+			err  error
+			code int
+			body string
+		}{
+			{err: fmt.Errorf("initialize session, %w", status.Error(codes.Unauthenticated, "invalid token")), code: http.StatusUnauthorized, body: "invalid token"},
+			{err: status.Error(codes.PermissionDenied, "forbidden"), code: http.StatusForbidden, body: "forbidden"},
+			// 不是gRPC status的错误不能泄露到客户端
+			{err: fmt.Errorf("initialize session, %w", errors.New("dial tcp 10.0.0.1:6379: connection refused")), code: http.StatusUnauthorized, body: "Unauthorized"},
+		} {
+			respC, sess := get()
+
+			// 鉴权之前不能写入响应头
+			select {
+			case <-respC:
+				t.Fatal("response header written before authentication")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			sess.(rejectableSession).reject(c.err)
+			_ = sess.Close()
+
+			resp := <-respC
+			if resp == nil {
+				t.FailNow()
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != c.code {
+				t.Fatalf("unexpected status code, expected %d, got %d", c.code, resp.StatusCode)
+			} else if text := strings.TrimSpace(string(body)); text != c.body {
+				t.Fatalf("unexpected body, expected %q, got %q", c.body, text)
+			} else if ct := resp.Header.Get("Content-Type"); strings.HasPrefix(ct, "text/event-stream") {
+				t.Fatalf("unexpected content type, %s", ct)
+			}
+		}
+	})
+
+	t.Run("accept", func(t *testing.T) {
+		respC, sess := get()
+		defer sess.Close()
+
+		if err := sess.(listenerSession).accept(); err != nil {
+			t.Fatalf("accept, %v", err)
+		}
+
+		resp := <-respC
+		if resp == nil {
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status code, %d", resp.StatusCode)
+		} else if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("unexpected content type, %s", ct)
+		}
+
+		if err := sess.Send(&nh.Reply{Code: 5}); err != nil {
+			t.Fatalf("send reply, %v", err)
+		}
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event, %v", err)
+		} else if !strings.HasPrefix(line, "data: ") || !strings.Contains(line, `"code":5`) {
+			t.Fatalf("unexpected event, %q", line)
+		}
+	})
+}
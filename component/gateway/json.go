@@ -0,0 +1,413 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/joyparty/gokit"
+	"github.com/joyparty/nodehub/cluster"
+	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/proto/nh"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	// 与protoc-gen-go-nodehub使用的自定义选项名称保持一致
+	optionReplyCode    = protoreflect.Name("reply_code")
+	optionReplyService = protoreflect.Name("reply_service")
+
+	// 获取服务描述信息的超时时间
+	reflectionTimeout = 5 * time.Second
+	// 获取服务描述信息失败后，在此时间内直接返回之前的错误，不再重复获取
+	reflectionFailureTTL = 10 * time.Second
+)
+
+// JSONCodec 在json与protobuf之间转换客户端请求及响应
+//
+// Request/Reply本身使用protojson编码，其中的data字段如果是json object，
+// 会根据从服务节点获取的protobuf描述信息转换为对应的protobuf message，否则按照protojson的规则作为base64字符串处理
+//
+// 服务描述信息通过gRPC反射服务获取，服务节点需要调用rpc.GRPCServer.EnableReflection()开启反射服务。
+// 响应的消息类型通过方法及消息上的reply_code、reply_service选项确定，与protoc-gen-go-nodehub的约定相同
+type JSONCodec struct {
+	registry *cluster.Registry
+	services *gokit.MapOf[int32, *jsonService]
+	failures *gokit.MapOf[int32, jsonLoadFailure]
+	loading  singleflight.Group
+}
+
+// jsonLoadFailure 获取服务描述信息失败的记录
+type jsonLoadFailure struct {
+	err   error
+	until time.Time
+}
+
+// NewJSONCodec 构造函数
+func NewJSONCodec(registry *cluster.Registry) *JSONCodec {
+	jc := &JSONCodec{
+		registry: registry,
+		services: gokit.NewMapOf[int32, *jsonService](),
+		failures: gokit.NewMapOf[int32, jsonLoadFailure](),
+	}
+
+	// 服务节点更新之后，重新获取描述信息
+	reset := func(entry cluster.NodeEntry) {
+		for _, desc := range entry.GRPC.Services {
+			jc.services.Delete(desc.Code)
+			jc.failures.Delete(desc.Code)
+		}
+	}
+	registry.SubscribeUpdate(reset)
+	registry.SubscribeDelete(reset)
+
+	return jc
+}
+
+// DecodeRequest 解码json格式的请求
+func (jc *JSONCodec) DecodeRequest(data []byte, req *nh.Request) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("unmarshal json, %w", err)
+	}
+
+	payload, ok := fields["data"]
+	if ok && bytes.HasPrefix(bytes.TrimSpace(payload), []byte("{")) {
+		delete(fields, "data")
+	} else {
+		payload = nil
+	}
+
+	envelope, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("marshal envelope, %w", err)
+	}
+	if err := protojson.Unmarshal(envelope, req); err != nil {
+		return fmt.Errorf("unmarshal request, %w", err)
+	}
+
+	if payload == nil {
+		return nil
+	}
+	return jc.decodeData(req, payload)
+}
+
+// decodeData 把json object转换为请求方法对应的protobuf message
+func (jc *JSONCodec) decodeData(req *nh.Request, payload []byte) error {
+	if jc == nil {
+		return errors.New("json codec is not configured")
+	}
+
+	svc, err := jc.loadService(req.GetServiceCode())
+	if err != nil {
+		return fmt.Errorf("load service, %w", err)
+	}
+
+	md, ok := svc.methods[protoreflect.Name(req.GetMethod())]
+	if !ok {
+		return fmt.Errorf("unknown method %q", req.GetMethod())
+	}
+
+	msg := dynamicpb.NewMessage(md.Input())
+	if err := protojson.Unmarshal(payload, msg); err != nil {
+		return fmt.Errorf("unmarshal request data, %w", err)
+	}
+
+	req.Data, err = proto.MarshalOptions{}.MarshalAppend(req.Data[:0], msg)
+	if err != nil {
+		return fmt.Errorf("marshal request data, %w", err)
+	}
+	return nil
+}
+
+// EncodeReply 把响应编码为json格式，无法确定data消息类型时，data按照base64编码
+func (jc *JSONCodec) EncodeReply(reply *nh.Reply) ([]byte, error) {
+	envelope := &nh.Reply{
+		RequestId:   reply.GetRequestId(),
+		ServiceCode: reply.GetServiceCode(),
+		Code:        reply.GetCode(),
+		Unreliable:  reply.GetUnreliable(),
+	}
+
+	md, ok := jc.replyType(reply.GetServiceCode(), reply.GetCode())
+	if !ok {
+		envelope.Data = reply.GetData()
+		return protojson.Marshal(envelope)
+	}
+
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(reply.GetData(), msg); err != nil {
+		return nil, fmt.Errorf("unmarshal reply data, %w", err)
+	}
+
+	payload, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal reply data, %w", err)
+	}
+
+	data, err := protojson.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("marshal reply, %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("unmarshal envelope, %w", err)
+	}
+	fields["data"] = payload
+
+	return json.Marshal(fields)
+}
+
+func (jc *JSONCodec) replyType(serviceCode, code int32) (protoreflect.MessageDescriptor, bool) {
+	if code == 0 {
+		return (&emptypb.Empty{}).ProtoReflect().Descriptor(), true
+	}
+
+	if t, ok := nh.GetReplyType(serviceCode, code); ok {
+		return reflect.New(t).Interface().(proto.Message).ProtoReflect().Descriptor(), true
+	}
+
+	if jc == nil || serviceCode == 0 {
+		return nil, false
+	}
+
+	svc, err := jc.loadService(serviceCode)
+	if err != nil {
+		logger.Error("load service descriptor", "error", err, "service", serviceCode)
+		return nil, false
+	}
+
+	md, ok := svc.replies[code]
+	return md, ok
+}
+
+// loadService 获取服务描述信息，同一个服务同时只会有一个获取过程，失败的结果会缓存一段时间
+func (jc *JSONCodec) loadService(code int32) (*jsonService, error) {
+	if svc, ok := jc.services.Load(code); ok {
+		return svc, nil
+	} else if f, ok := jc.failures.Load(code); ok && time.Now().Before(f.until) {
+		return nil, f.err
+	}
+
+	v, err, _ := jc.loading.Do(strconv.Itoa(int(code)), func() (any, error) {
+		svc, err := jc.fetchService(code)
+		if err != nil {
+			jc.failures.Store(code, jsonLoadFailure{err: err, until: time.Now().Add(reflectionFailureTTL)})
+			return nil, err
+		}
+
+		jc.failures.Delete(code)
+		jc.services.Store(code, svc)
+		return svc, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*jsonService), nil
+}
+
+func (jc *JSONCodec) fetchService(code int32) (*jsonService, error) {
+	desc, ok := jc.registry.GetGRPCDesc(code)
+	if !ok {
+		return nil, fmt.Errorf("unknown service %d", code)
+	}
+
+	nodeID, err := jc.registry.PickGRPCNode(code)
+	if err != nil {
+		return nil, fmt.Errorf("pick grpc node, %w", err)
+	}
+
+	conn, err := jc.registry.GetGRPCConn(nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("get grpc conn, %w", err)
+	}
+
+	files, err := fetchFileDescriptors(conn, desc.Name)
+	if err != nil {
+		return nil, fmt.Errorf("fetch file descriptors, %w", err)
+	}
+
+	return newJSONService(files, code, desc.Name)
+}
+
+// fetchFileDescriptors 通过gRPC反射服务获取服务所在的proto文件及其依赖
+func fetchFileDescriptors(conn *grpc.ClientConn, serviceName string) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), reflectionTimeout)
+	defer cancel()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("open reflection stream, %w", err)
+	}
+	defer func() { _ = stream.CloseSend() }()
+
+	files := map[string]*descriptorpb.FileDescriptorProto{}
+	request := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: serviceName,
+		},
+	}
+
+	for request != nil {
+		if err := stream.Send(request); err != nil {
+			return nil, fmt.Errorf("send reflection request, %w", err)
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("recv reflection response, %w", err)
+		} else if errResp := resp.GetErrorResponse(); errResp != nil {
+			return nil, fmt.Errorf("reflection error, %s", errResp.GetErrorMessage())
+		}
+
+		for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(data, fd); err != nil {
+				return nil, fmt.Errorf("unmarshal file descriptor, %w", err)
+			}
+			files[fd.GetName()] = fd
+		}
+
+		// 服务器端已经发送过的依赖不会重复发送，缺少的依赖逐个获取
+		request = nil
+	FIND:
+		for _, fd := range files {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := files[dep]; !ok {
+					request = &reflectionpb.ServerReflectionRequest{
+						MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{
+							FileByFilename: dep,
+						},
+					}
+					break FIND
+				}
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range files {
+		set.File = append(set.File, fd)
+	}
+	return protodesc.NewFiles(set)
+}
+
+// jsonService 服务的方法及响应消息描述信息
+type jsonService struct {
+	methods map[protoreflect.Name]protoreflect.MethodDescriptor
+	replies map[int32]protoreflect.MessageDescriptor // reply code => message
+}
+
+func newJSONService(files *protoregistry.Files, code int32, serviceName string) (*jsonService, error) {
+	d, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("find service %s, %w", serviceName, err)
+	}
+
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+
+	// 自定义选项在反射得到的描述信息内是未知字段，需要使用动态类型重新解析
+	extTypes := &protoregistry.Types{}
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		registerExtensions(extTypes, fd)
+		return true
+	})
+
+	svc := &jsonService{
+		methods: map[protoreflect.Name]protoreflect.MethodDescriptor{},
+		replies: map[int32]protoreflect.MessageDescriptor{},
+	}
+
+	methods := sd.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		svc.methods[md.Name()] = md
+
+		options := parseOptions(extTypes, md.Options())
+		if replyCode, ok := options[optionReplyCode]; ok {
+			svc.replies[replyCode] = md.Output()
+		}
+	}
+
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		messages := fd.Messages()
+		for i := 0; i < messages.Len(); i++ {
+			md := messages.Get(i)
+
+			options := parseOptions(extTypes, md.Options())
+			replyService, ok1 := options[optionReplyService]
+			replyCode, ok2 := options[optionReplyCode]
+			if ok1 && ok2 && replyService == code {
+				svc.replies[replyCode] = md
+			}
+		}
+		return true
+	})
+
+	return svc, nil
+}
+
+func registerExtensions(extTypes *protoregistry.Types, descs interface {
+	Messages() protoreflect.MessageDescriptors
+	Extensions() protoreflect.ExtensionDescriptors
+},
+) {
+	mds := descs.Messages()
+	for i := 0; i < mds.Len(); i++ {
+		registerExtensions(extTypes, mds.Get(i))
+	}
+
+	xds := descs.Extensions()
+	for i := 0; i < xds.Len(); i++ {
+		_ = extTypes.RegisterExtension(dynamicpb.NewExtensionType(xds.Get(i)))
+	}
+}
+
+// parseOptions 解析整数及枚举类型的自定义选项
+func parseOptions(extTypes *protoregistry.Types, options proto.Message) map[protoreflect.Name]int32 {
+	result := map[protoreflect.Name]int32{}
+
+	data, err := proto.Marshal(options)
+	if err != nil || len(data) == 0 {
+		return result
+	}
+
+	msg := options.ProtoReflect().Type().New().Interface()
+	if err := (proto.UnmarshalOptions{Resolver: extTypes}).Unmarshal(data, msg); err != nil {
+		return result
+	}
+
+	msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if !fd.IsExtension() {
+			return true
+		}
+
+		switch fd.Kind() {
+		case protoreflect.EnumKind:
+			result[fd.Name()] = int32(v.Enum())
+		case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+			result[fd.Name()] = int32(v.Int())
+		}
+		return true
+	})
+	return result
+}
@@ -0,0 +1,218 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/joyparty/gokit"
+	"github.com/joyparty/nodehub/cluster"
+	"github.com/joyparty/nodehub/internal/codec"
+	"github.com/joyparty/nodehub/internal/kcp"
+	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
+	"github.com/joyparty/nodehub/proto/nh"
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	_ Session     = &kcpSession{}
+	_ Transporter = &kcpServer{}
+)
+
+// KCPConfig KCP传输参数
+type KCPConfig = kcp.Config
+
+// DefaultKCPConfig 默认KCP配置，nodelay极速模式
+func DefaultKCPConfig() *KCPConfig {
+	return kcp.DefaultConfig()
+}
+
+// kcpServer kcp网关服务
+type kcpServer struct {
+	listenAddr string
+	packetConn net.PacketConn
+	config     *KCPConfig
+	listener   *kcp.Listener
+}
+
+// NewKCPServer 构造函数，config为nil时使用默认配置
+func NewKCPServer(listenAddr string, config *KCPConfig) Transporter {
+	return &kcpServer{
+		listenAddr: listenAddr,
+		config:     config,
+	}
+}
+
+// BindKCPServer 绑定KCP服务器
+func BindKCPServer(conn net.PacketConn, config *KCPConfig) Transporter {
+	return &kcpServer{
+		listenAddr: conn.LocalAddr().String(),
+		packetConn: conn,
+		config:     config,
+	}
+}
+
+// CompleteNodeEntry 补全节点信息
+func (ks *kcpServer) CompleteNodeEntry(entry *cluster.NodeEntry) {
+	entry.Entrance = fmt.Sprintf("kcp://%s", ks.listenAddr)
+}
+
+func (ks *kcpServer) Serve(ctx context.Context) (chan Session, error) {
+	if ks.packetConn != nil {
+		ks.listener = kcp.ServeConn(ks.packetConn, ks.config)
+	} else {
+		l, err := kcp.Listen(ks.listenAddr, ks.config)
+		if err != nil {
+			return nil, fmt.Errorf("listen, %w", err)
+		}
+		ks.listener = l
+	}
+
+	ch := make(chan Session)
+	go func() {
+		defer close(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			conn, err := ks.listener.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			} else if err != nil {
+				logger.Error("kcp accept", "error", err)
+				continue
+			}
+
+			ch <- newKCPSession(conn)
+		}
+	}()
+
+	return ch, nil
+}
+
+// Shutdown 停止服务
+func (ks *kcpServer) Shutdown(ctx context.Context) error {
+	return ks.listener.Close()
+}
+
+type kcpSession struct {
+	id         string
+	conn       *kcp.Conn
+	md         metadata.MD
+	lastRWTime gokit.ValueOf[time.Time]
+	metrics    *metrics.Metrics
+	closeOnce  sync.Once
+}
+
+func newKCPSession(conn *kcp.Conn) *kcpSession {
+	ks := &kcpSession{
+		id:         ulid.Make().String(),
+		conn:       conn,
+		md:         metadata.New(nil),
+		lastRWTime: gokit.NewValueOf[time.Time](),
+	}
+	ks.lastRWTime.Store(time.Now())
+
+	return ks
+}
+
+func (ks *kcpSession) Type() string {
+	return "kcp"
+}
+
+func (ks *kcpSession) ID() string {
+	return ks.id
+}
+
+func (ks *kcpSession) SetID(id string) {
+	ks.id = id
+}
+
+func (ks *kcpSession) SetMetadata(md metadata.MD) {
+	ks.md = md
+}
+
+func (ks *kcpSession) MetadataCopy() metadata.MD {
+	return ks.md.Copy()
+}
+
+func (ks *kcpSession) setMetrics(m *metrics.Metrics) {
+	ks.metrics = m
+}
+
+func (ks *kcpSession) Recv(req *nh.Request) (err error) {
+	defer func() {
+		if errors.Is(err, net.ErrClosed) || errors.Is(err, kcp.ErrDeadLink) {
+			err = io.EOF
+		}
+	}()
+
+	msg := codec.GetMessage()
+	defer codec.PutMessage(msg)
+
+	for {
+		if err = codec.ReadMessage(ks.conn, msg); err != nil {
+			return fmt.Errorf("read message, %w", err)
+		}
+		ks.lastRWTime.Store(time.Now())
+
+		if msg.Len() > 0 {
+			ks.metrics.IncrPayloadSize(ks.Type(), msg.Len())
+
+			if err := proto.Unmarshal(msg.Bytes(), req); err != nil {
+				return fmt.Errorf("unmarshal request, %w", err)
+			}
+			return nil
+		}
+	}
+}
+
+func (ks *kcpSession) Send(reply *nh.Reply) error {
+	return codec.SendReply(reply, func(data []byte) error {
+		_ = ks.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+		_, err := ks.conn.Write(data)
+		if err == nil {
+			ks.lastRWTime.Store(time.Now())
+		}
+		return err
+	})
+}
+
+func (ks *kcpSession) LocalAddr() string {
+	return ks.conn.LocalAddr().String()
+}
+
+func (ks *kcpSession) RemoteAddr() string {
+	return ks.conn.RemoteAddr().String()
+}
+
+func (ks *kcpSession) LastRWTime() time.Time {
+	return ks.lastRWTime.Load()
+}
+
+func (ks *kcpSession) Close() (err error) {
+	ks.closeOnce.Do(func() {
+		err = ks.conn.Close()
+	})
+	return
+}
+
+func (ks *kcpSession) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", ks.id),
+		slog.String("type", "kcp"),
+		slog.String("addr", ks.RemoteAddr()),
+	)
+}
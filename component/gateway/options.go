@@ -59,12 +59,27 @@ type Options struct {
 	// 断开连接拦截器
 	// 在连接断开前执行自定操作
 	DisconnectInterceptor DisconnectInterceptor
+
+	// 最大会话数量，包括正在初始化的会话，0表示不限制
+	MaxSessions int
+
+	// 每个IP地址的最大会话数量，0表示不限制
+	MaxSessionsPerIP int
+
+	// 会话初始化超时时间，默认10秒，0表示不限制
+	// 超时后会强制断开连接，避免客户端不鉴权一直占用连接
+	InitTimeout time.Duration
+
+	// 会话初始化完成之前，允许客户端上行的最大字节数，0表示不限制
+	// 只统计Initializer内通过Session.Recv()读取的请求，对http会话无效
+	PreAuthBytes int
 }
 
 func newOptions() *Options {
 	return &Options{
 		KeepaliveInterval:     1 * time.Minute,
 		RequstTimeout:         5 * time.Second,
+		InitTimeout:           10 * time.Second,
 		RequestInterceptor:    defaultRequestInterceptor,
 		ConnectInterceptor:    defaultConnectInterceptor,
 		DisconnectInterceptor: defaultDisconnectInterceptor,
@@ -206,6 +221,34 @@ func WithRequestTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithMaxSessions 设置最大会话数量，0表示不限制
+func WithMaxSessions(n int) Option {
+	return func(opt *Options) {
+		opt.MaxSessions = n
+	}
+}
+
+// WithMaxSessionsPerIP 设置每个IP地址的最大会话数量，0表示不限制
+func WithMaxSessionsPerIP(n int) Option {
+	return func(opt *Options) {
+		opt.MaxSessionsPerIP = n
+	}
+}
+
+// WithInitTimeout 设置会话初始化超时时间，默认10秒，0表示不限制
+func WithInitTimeout(timeout time.Duration) Option {
+	return func(opt *Options) {
+		opt.InitTimeout = timeout.Abs()
+	}
+}
+
+// WithPreAuthBytes 设置会话初始化完成之前，允许客户端上行的最大字节数，0表示不限制
+func WithPreAuthBytes(n int) Option {
+	return func(opt *Options) {
+		opt.PreAuthBytes = n
+	}
+}
+
 // GoPool goroutine pool
 type GoPool interface {
 	Submit(task func()) error
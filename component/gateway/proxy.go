@@ -8,12 +8,15 @@ import (
 	"log/slog"
 	"net"
 	"path"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/joyparty/gokit"
 	"github.com/oklog/ulid/v2"
 	"github.com/panjf2000/ants/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -24,9 +27,10 @@ import (
 	"github.com/joyparty/nodehub/cluster"
 	"github.com/joyparty/nodehub/component/rpc"
 	"github.com/joyparty/nodehub/event"
-	"github.com/joyparty/nodehub/internal/metrics"
 	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
 	"github.com/joyparty/nodehub/proto/nh"
+	"github.com/joyparty/nodehub/tracing"
 )
 
 var (
@@ -62,13 +66,37 @@ type Session interface {
 	LogValue() slog.Value
 }
 
+// transientSession 只处理单个请求的会话，例如http请求
+type transientSession interface {
+	transient() bool
+}
+
+// listenerSession 只接收主动下行消息的会话，例如server-sent events
+type listenerSession interface {
+	listener() bool
+	// accept 鉴权通过之后调用，开始下行消息
+	accept() error
+}
+
+// metricsSession 在传输层统计上行数据大小的会话
+type metricsSession interface {
+	setMetrics(m *metrics.Metrics)
+}
+
+// rejectableSession 鉴权失败时可以告知客户端原因的会话
+type rejectableSession interface {
+	reject(err error)
+}
+
 // Proxy 客户端会话运行环境
 type Proxy struct {
 	nodeID     string
 	opts       *Options
 	sessions   *sessionHub
+	listeners  *listenerHub
 	stateTable *stateTable
 	cleanJobs  *gokit.MapOf[string, *time.Timer]
+	admission  *admission
 	done       chan struct{}
 }
 
@@ -78,6 +106,7 @@ func NewProxy(nodeID ulid.ULID, opt ...Option) (*Proxy, error) {
 		nodeID:     nodeID.String(),
 		opts:       newOptions(),
 		sessions:   newSessionHub(),
+		listeners:  newListenerHub(),
 		stateTable: newStateTable(),
 		cleanJobs:  gokit.NewMapOf[string, *time.Timer](),
 		done:       make(chan struct{}),
@@ -90,6 +119,8 @@ func NewProxy(nodeID ulid.ULID, opt ...Option) (*Proxy, error) {
 	if err := p.opts.Validate(); err != nil {
 		return nil, err
 	}
+
+	p.admission = newAdmission(p.opts.MaxSessions, p.opts.MaxSessionsPerIP)
 	return p, nil
 }
 
@@ -139,6 +170,7 @@ func (p *Proxy) Start(ctx context.Context) error {
 func (p *Proxy) Stop(ctx context.Context) {
 	close(p.done)
 	p.sessions.Close()
+	p.listeners.Close()
 
 	if err := p.opts.Transporter.Shutdown(ctx); err != nil && !errors.Is(err, net.ErrClosed) {
 		logger.Error("shutdown gateway transporter", "error", err)
@@ -154,6 +186,16 @@ func (p *Proxy) NewGRPCService() nh.GatewayServer {
 }
 
 func (p *Proxy) init(ctx context.Context) {
+	type metricsSetter interface {
+		SetMetrics(*metrics.Metrics)
+	}
+
+	// 消息总线没有关联服务注册表，使用网关服务注册表的metrics
+	p.opts.EventBus.SetMetrics(p.metrics())
+	if v, ok := p.opts.Multicast.(metricsSetter); ok {
+		v.SetMetrics(p.metrics())
+	}
+
 	// 有状态路由更新
 	p.opts.EventBus.Subscribe(ctx, func(ev event.NodeAssign, _ time.Time) {
 		if err := p.submitTask(func() {
@@ -195,9 +237,22 @@ func (p *Proxy) init(ctx context.Context) {
 			"code", msg.GetContent().GetCode(),
 			"time", msg.GetTime().AsTime().Format(time.RFC3339),
 		)
+		var misses int
 		for _, sessID := range msg.GetReceiver() {
 			sessID := sessID
+
+			// 长连接会话及server-sent events等监听会话都需要下行
+			targets := p.listeners.Load(sessID)
 			if sess, ok := p.sessions.Load(sessID); ok {
+				targets = append(targets, sess)
+			}
+			if len(targets) == 0 {
+				misses++
+				continue
+			}
+
+			for _, sess := range targets {
+				sess := sess
 				if err := p.submitTask(func() {
 					logger.Debug("send multicast",
 						"receiver", sessID,
@@ -212,6 +267,7 @@ func (p *Proxy) init(ctx context.Context) {
 				}
 			}
 		}
+		p.metrics().ObserveMulticast(len(msg.GetReceiver()), misses)
 	})
 
 	p.opts.Registry.SubscribeDelete(func(entry cluster.NodeEntry) {
@@ -226,6 +282,27 @@ func (p *Proxy) handleSession(ctx context.Context, sess Session) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	if ms, ok := sess.(metricsSession); ok {
+		ms.setMetrics(p.metrics())
+	}
+
+	release, reason, err := p.admission.acquire(sess.RemoteAddr())
+	if err != nil {
+		p.metrics().IncrSessionRejected(sess.Type(), reason)
+		logger.Warn("reject session", "error", err, "addr", sess.RemoteAddr())
+		_ = sess.Close()
+		return
+	}
+	defer release()
+
+	if ts, ok := sess.(transientSession); ok && ts.transient() {
+		p.handleTransientSession(ctx, sess)
+		return
+	} else if ls, ok := sess.(listenerSession); ok && ls.listener() {
+		p.handleListenerSession(ctx, sess, ls)
+		return
+	}
+
 	logger.Info("handle connection", "addr", sess.RemoteAddr())
 	if err := p.onConnect(ctx, sess); err != nil {
 		if !errors.Is(err, io.EOF) {
@@ -236,8 +313,9 @@ func (p *Proxy) handleSession(ctx context.Context, sess Session) {
 	}
 	defer p.onDisconnect(ctx, sess)
 
-	metrics.IncrGatewaySession(sess.Type())
-	defer metrics.DecrGatewaySession(sess.Type())
+	connectedAt := time.Now()
+	p.metrics().IncrGatewaySession(sess.Type())
+	defer func() { p.metrics().DecrGatewaySession(sess.Type(), time.Since(connectedAt)) }()
 
 	logVars := []any{
 		"session", sess,
@@ -247,7 +325,7 @@ func (p *Proxy) handleSession(ctx context.Context, sess Session) {
 	logger.Info("session connected", logVars...)
 	defer logger.Info("session disconnected", logVars...)
 
-	var prevRequestID uint32
+	var requestIDs replayWindow
 
 	for {
 		select {
@@ -267,30 +345,21 @@ func (p *Proxy) handleSession(ctx context.Context, sess Session) {
 			}
 			return
 		}
-
-		if prevRequestID > 0 && req.GetId() <= prevRequestID {
-			logger.Error("request id has already been used ", "session", sess, "prev", prevRequestID, "current", req.GetId())
+		if ok, err := requestIDs.acceptRequest(req); err != nil {
+			logger.Error("check request id", "error", err, "session", sess, "current", req.GetId())
+			requestPool.Put(req)
 			return
+		} else if !ok {
+			requestPool.Put(req)
+			continue
 		}
-		prevRequestID = req.GetId()
 
 		if err := p.submitTask(func() {
 			defer requestPool.Put(req)
 
 			if err := p.handleRequest(ctx, sess, req); err != nil {
 				if s, ok := status.FromError(err); ok {
-					if s.Code() == codes.Unknown {
-						// unknown错误，不下行详细的错误描述，避免泄露信息到客户端
-						s = status.New(codes.Unknown, "unknown error")
-					}
-
-					reply, _ := nh.NewReply(int32(nh.ReplyCode_RPC_ERROR), &nh.RPCError{
-						RequestService: req.GetServiceCode(),
-						RequestMethod:  req.GetMethod(),
-						Status:         s.Proto(),
-					})
-					reply.RequestId = req.GetId()
-					p.sendReply(sess, reply)
+					p.replyError(sess, req, s)
 				}
 			}
 		}); err != nil {
@@ -301,17 +370,119 @@ func (p *Proxy) handleSession(ctx context.Context, sess Session) {
 	}
 }
 
+// handleTransientSession 处理单次请求会话
+//
+// 这类会话只经过Initializer鉴权，不会登记到会话列表，也不会发布连接事件，
+// 避免影响同一个用户的长连接会话
+func (p *Proxy) handleTransientSession(ctx context.Context, sess Session) {
+	defer sess.Close()
+
+	if err := p.initSession(ctx, sess); err != nil {
+		logger.Error("initialize transient session", "error", err, "addr", sess.RemoteAddr())
+		if rs, ok := sess.(rejectableSession); ok {
+			rs.reject(err)
+		}
+		return
+	}
+
+	req := requestPool.Get()
+	nh.ResetRequest(req)
+	defer requestPool.Put(req)
+
+	if err := sess.Recv(req); err != nil {
+		if !errors.Is(err, io.EOF) {
+			logger.Error("recv request", "error", err, "session", sess)
+		}
+		return
+	}
+	if err := p.handleRequest(ctx, sess, req); err != nil {
+		// 客户端需要等待响应，所以任何错误都需要下行
+		s, _ := status.FromError(err)
+		p.replyError(sess, req, s)
+	}
+}
+
+// handleListenerSession 处理只接收下行消息的会话
+//
+// 这类会话经过Initializer鉴权之后登记到监听列表，与同一个用户的长连接会话一起接收主动下行消息，
+// 不会替换长连接会话，也不会发布连接事件
+func (p *Proxy) handleListenerSession(ctx context.Context, sess Session, ls listenerSession) {
+	defer sess.Close()
+
+	if err := p.initSession(ctx, sess); err != nil {
+		logger.Error("initialize listener session", "error", err, "addr", sess.RemoteAddr())
+		if rs, ok := sess.(rejectableSession); ok {
+			rs.reject(err)
+		}
+		return
+	}
+
+	if err := ls.accept(); err != nil {
+		logger.Error("accept listener session", "error", err, "session", sess)
+		return
+	}
+
+	if !p.listeners.Store(sess) {
+		return
+	}
+	defer p.listeners.Delete(sess)
+
+	connectedAt := time.Now()
+	p.metrics().IncrGatewaySession(sess.Type())
+	defer func() { p.metrics().DecrGatewaySession(sess.Type(), time.Since(connectedAt)) }()
+
+	logVars := []any{
+		"session", sess,
+		"gateway", p.nodeID,
+	}
+
+	logger.Info("listener connected", logVars...)
+	defer logger.Info("listener disconnected", logVars...)
+
+	// 没有上行请求，阻塞到连接断开
+	req := &nh.Request{}
+	if err := sess.Recv(req); err != nil && !errors.Is(err, io.EOF) {
+		logger.Error("recv request", "error", err, "session", sess)
+	}
+}
+
+// replyError 把错误以RPCError的形式下行到客户端
+func (p *Proxy) replyError(sess Session, req *nh.Request, s *status.Status) {
+	if s.Code() == codes.Unknown {
+		// unknown错误，不下行详细的错误描述，避免泄露信息到客户端
+		s = status.New(codes.Unknown, "unknown error")
+	}
+
+	reply, _ := nh.NewReply(int32(nh.ReplyCode_RPC_ERROR), &nh.RPCError{
+		RequestService: req.GetServiceCode(),
+		RequestMethod:  req.GetMethod(),
+		Status:         s.Proto(),
+	})
+	reply.RequestId = req.GetId()
+	p.sendReply(sess, reply)
+
+	p.metrics().IncrRPCError(req.GetServiceCode(), s.Code())
+}
+
 // 以status.Error()构造的错误，都会被下行通知到客户端
 func (p *Proxy) handleRequest(ctx context.Context, sess Session, req *nh.Request) (err error) {
 	var (
 		conn   *grpc.ClientConn
 		desc   cluster.GRPCServiceDesc
 		method string
+		nodeID ulid.ULID
 	)
 
 	logRequest := p.logRequest(ctx, sess, req)
 	defer func() { logRequest(conn, desc, method, err) }()
 
+	ctx, span := tracing.Start(ctx, "gateway.request", trace.SpanKindServer,
+		attribute.String("nodehub.session.id", sess.ID()),
+		attribute.Int("nodehub.service.code", int(req.GetServiceCode())),
+		attribute.String("rpc.method", req.GetMethod()),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	pass, err := p.opts.RequestInterceptor(ctx, sess, req)
 	if err != nil {
 		return fmt.Errorf("request interceptor, %w", err)
@@ -326,10 +497,11 @@ func (p *Proxy) handleRequest(ctx context.Context, sess Session, req *nh.Request
 		return status.Errorf(codes.PermissionDenied, "request private service")
 	}
 
-	conn, err = p.getUpstream(sess, req, desc)
+	nodeID, conn, err = p.getUpstream(sess, req, desc)
 	if err != nil {
 		return
 	}
+	span.SetAttributes(upstreamAttributes(nodeID, conn)...)
 
 	md := sess.MetadataCopy()
 	md.Set(rpc.MDTransactionID, ulid.Make().String())
@@ -337,9 +509,18 @@ func (p *Proxy) handleRequest(ctx context.Context, sess Session, req *nh.Request
 	md.Set(rpc.MDGateway, p.nodeID)
 	ctx = metadata.NewOutgoingContext(ctx, md)
 
+	timeout := p.opts.RequstTimeout
+	if v := desc.TimeoutOf(req.GetMethod()); v > 0 {
+		timeout = v
+	}
+	// 客户端指定的超时时间不能超过服务器端配置
+	if v := time.Duration(req.GetTimeout()) * time.Millisecond; v > 0 && (timeout <= 0 || v < timeout) {
+		timeout = v
+	}
+
 	var cancel context.CancelFunc
-	if timemout := p.opts.RequstTimeout; timemout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, timemout)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
 		defer cancel()
 	}
 
@@ -353,9 +534,30 @@ func (p *Proxy) handleRequest(ctx context.Context, sess Session, req *nh.Request
 	defer replyPool.Put(output)
 
 	method = path.Join(desc.Path, req.Method)
-	if err = conn.Invoke(ctx, method, input, output); err != nil {
-		// 这里不要用fmt.Errorf()包装，否则fmt.Errorf()会污染status.Status.Message()，导致日志记录不必要的重复内容
-		return err
+	policy, retry := desc.RetryPolicyOf(req.GetMethod())
+	tried := []ulid.ULID{}
+	for attempt := 1; ; attempt++ {
+		if err = conn.Invoke(ctx, method, input, output); err == nil {
+			break
+		} else if !retry || attempt >= policy.MaxAttempts || !policy.IsRetryable(err) {
+			// 这里不要用fmt.Errorf()包装，否则fmt.Errorf()会污染status.Status.Message()，导致日志记录不必要的重复内容
+			return err
+		}
+
+		// 在请求超时时间内，换一个节点重试，没有其它可用节点时返回最后一次的错误
+		tried = append(tried, nodeID)
+		if !sleepContext(ctx, policy.BackoffOf(attempt)) {
+			return err
+		}
+
+		nextID, nextConn, nextErr := p.reallocUpstream(sess, req, tried)
+		if nextErr != nil {
+			return err
+		}
+		nodeID, conn = nextID, nextConn
+		nh.ResetReply(output)
+		span.AddEvent("retry", trace.WithAttributes(append(upstreamAttributes(nodeID, conn), attribute.Int("attempt", attempt+1))...))
+		span.SetAttributes(upstreamAttributes(nodeID, conn)...)
 	}
 
 	if req.GetNoReply() {
@@ -364,13 +566,48 @@ func (p *Proxy) handleRequest(ctx context.Context, sess Session, req *nh.Request
 
 	output.RequestId = req.GetId()
 	output.ServiceCode = req.GetServiceCode()
+	if req.GetUnreliable() {
+		output.Unreliable = true
+	}
 	p.sendReply(sess, output)
 	return nil
 }
 
-func (p *Proxy) onConnect(ctx context.Context, sess Session) error {
-	userID, md, err := p.opts.Initializer(ctx, sess)
+// initSession 调用Initializer初始化会话
+func (p *Proxy) initSession(ctx context.Context, sess Session) (err error) {
+	ctx, span := tracing.Start(ctx, "gateway.session.init", trace.SpanKindInternal, sessionAttributes(sess)...)
+	defer func() {
+		if err == nil {
+			span.SetAttributes(attribute.String("nodehub.session.id", sess.ID()))
+		}
+		tracing.End(span, err)
+	}()
+
+	initSess := sess
+	if n := p.opts.PreAuthBytes; n > 0 {
+		// http会话需要保留原始类型，以便Initializer获取http请求
+		if _, ok := sess.(HTTPSession); !ok {
+			initSess = &budgetSession{Session: sess, remain: n}
+		}
+	}
+
+	if timeout := p.opts.InitTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		// Initializer可能阻塞在读取上，超时后直接关闭连接
+		timer := time.AfterFunc(timeout, func() { _ = sess.Close() })
+		defer timer.Stop()
+	}
+
+	userID, md, err := p.opts.Initializer(ctx, initSess)
 	if err != nil {
+		if errors.Is(err, errPreAuthBytes) {
+			p.metrics().IncrSessionRejected(sess.Type(), rejectPreAuthBytes)
+		} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			p.metrics().IncrSessionRejected(sess.Type(), rejectInitTimeout)
+		}
 		return fmt.Errorf("deny by initializer, %w", err)
 	} else if userID == "" {
 		return errors.New("empty userID")
@@ -380,6 +617,16 @@ func (p *Proxy) onConnect(ctx context.Context, sess Session) error {
 
 	sess.SetID(userID)
 	sess.SetMetadata(md)
+	return nil
+}
+
+func (p *Proxy) onConnect(ctx context.Context, sess Session) (err error) {
+	ctx, span := tracing.Start(ctx, "gateway.session.connect", trace.SpanKindServer, sessionAttributes(sess)...)
+	defer func() { tracing.End(span, err) }()
+
+	if err := p.initSession(ctx, sess); err != nil {
+		return err
+	}
 
 	if err := p.opts.ConnectInterceptor(ctx, sess); err != nil {
 		return err
@@ -413,6 +660,10 @@ func (p *Proxy) onConnect(ctx context.Context, sess Session) error {
 }
 
 func (p *Proxy) onDisconnect(ctx context.Context, sess Session) {
+	ctx, span := tracing.Start(ctx, "gateway.session.disconnect", trace.SpanKindServer,
+		append(sessionAttributes(sess), attribute.String("nodehub.session.id", sess.ID()))...)
+	defer span.End()
+
 	defer sess.Close()
 	p.opts.DisconnectInterceptor(ctx, sess)
 	p.sessions.Delete(sess)
@@ -433,8 +684,7 @@ func (p *Proxy) onDisconnect(ctx context.Context, sess Session) {
 	}))
 }
 
-func (p *Proxy) getUpstream(sess Session, req *nh.Request, desc cluster.GRPCServiceDesc) (conn *grpc.ClientConn, err error) {
-	var nodeID ulid.ULID
+func (p *Proxy) getUpstream(sess Session, req *nh.Request, desc cluster.GRPCServiceDesc) (nodeID ulid.ULID, conn *grpc.ClientConn, err error) {
 	// 无状态服务，根据负载均衡策略选择一个节点发送
 	if !desc.Stateful {
 		nodeID, err = p.opts.Registry.AllocGRPCNode(req.GetServiceCode(), sess)
@@ -489,6 +739,12 @@ func (p *Proxy) getUpstream(sess Session, req *nh.Request, desc cluster.GRPCServ
 	}()
 
 FINISH:
+	// 客户端指定或者路由表记录的节点，可能提供的是使用相同代码的其它服务
+	if p.opts.Registry.IsServiceConflict(req.GetServiceCode(), nodeID) {
+		err = status.Errorf(codes.Aborted, "node %s conflicts with service %d", nodeID, req.GetServiceCode())
+		return
+	}
+
 	conn, err = p.opts.Registry.GetGRPCConn(nodeID)
 	if err != nil {
 		err = status.Errorf(codes.Aborted, "get grpc conn, %v", err)
@@ -496,13 +752,27 @@ FINISH:
 	return
 }
 
+// reallocUpstream 请求失败之后，给无状态服务重新分配一个没有尝试过的节点
+func (p *Proxy) reallocUpstream(sess Session, req *nh.Request, tried []ulid.ULID) (nodeID ulid.ULID, conn *grpc.ClientConn, err error) {
+	nodeID, err = p.opts.Registry.AllocGRPCNodeExcept(req.GetServiceCode(), sess, tried)
+	if err != nil {
+		return
+	} else if p.opts.Registry.IsServiceConflict(req.GetServiceCode(), nodeID) {
+		err = fmt.Errorf("node %s conflicts with service %d", nodeID, req.GetServiceCode())
+		return
+	}
+
+	conn, err = p.opts.Registry.GetGRPCConn(nodeID)
+	return
+}
+
 func (p *Proxy) logRequest(ctx context.Context, sess Session, req *nh.Request) func(*grpc.ClientConn, cluster.GRPCServiceDesc, string, error) {
 	start := time.Now()
 
 	return func(upstream *grpc.ClientConn, desc cluster.GRPCServiceDesc, method string, err error) {
 		// 如果method为空，说明还没有到达请求阶段
 		if method != "" {
-			metrics.IncrGRPCRequests(method, err, time.Since(start))
+			p.metrics().IncrGRPCRequests(method, err, time.Since(start))
 		}
 
 		if err == nil && p.opts.RequestLogger == nil {
@@ -548,15 +818,51 @@ func (p *Proxy) logRequest(ctx context.Context, sess Session, req *nh.Request) f
 	}
 }
 
-func (p *Proxy) submitTask(task func()) error {
+func (p *Proxy) submitTask(task func()) (err error) {
 	if pool := p.opts.GoPool; pool != nil {
-		return pool.Submit(task)
+		err = pool.Submit(task)
+	} else {
+		err = ants.Submit(task)
+	}
+
+	if err != nil {
+		p.metrics().IncrGoPoolRejected()
+	}
+	return
+}
+
+// observeGoPool 记录goroutine池的使用情况，自定义的GoPool需要实现Running()和Cap()方法才能统计
+func (p *Proxy) observeGoPool() {
+	if p.opts.GoPool == nil {
+		p.metrics().SetGoPool(ants.Running(), ants.Cap(), -1)
+		return
 	}
-	return ants.Submit(task)
+
+	pool, ok := p.opts.GoPool.(interface {
+		Running() int
+		Cap() int
+	})
+	if !ok {
+		return
+	}
+
+	waiting := -1
+	if v, ok := pool.(interface{ Waiting() int }); ok {
+		waiting = v.Waiting()
+	}
+	p.metrics().SetGoPool(pool.Running(), pool.Cap(), waiting)
+}
+
+func (p *Proxy) metrics() *metrics.Metrics {
+	return p.opts.Registry.Metrics()
 }
 
 func (p *Proxy) sendReply(sess Session, reply *nh.Reply) {
-	if err := sess.Send(reply); err != nil {
+	start := time.Now()
+	err := sess.Send(reply)
+	p.metrics().ObserveSend(sess.Type(), err, time.Since(start))
+
+	if err != nil {
 		logger.Error("send reply",
 			"error", err,
 			"session", sess,
@@ -579,12 +885,46 @@ func (p *Proxy) removeZombie() {
 				}
 				return true
 			})
+
+			p.metrics().SetStatefulRoutes(p.stateTable.Count())
+			p.observeGoPool()
 		}
 	}
 }
 
 var emptyMessage = &emptypb.Empty{}
 
+func sessionAttributes(sess Session) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("nodehub.session.type", sess.Type()),
+		attribute.String("net.peer.addr", sess.RemoteAddr()),
+	}
+}
+
+func upstreamAttributes(nodeID ulid.ULID, conn *grpc.ClientConn) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("nodehub.upstream.node", nodeID.String()),
+		attribute.String("nodehub.upstream.endpoint", conn.Target()),
+	}
+}
+
+// sleepContext 等待指定时长，ctx结束时返回false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 func newEmptyMessage(data []byte) (*emptypb.Empty, error) {
 	if len(data) == 0 {
 		return emptyMessage, nil
@@ -646,3 +986,76 @@ func (h *sessionHub) Close() {
 		})
 	}
 }
+
+// listenerHub 监听会话集合，同一个用户可以同时存在多个监听会话
+type listenerHub struct {
+	mux       sync.RWMutex
+	listeners map[string]map[Session]struct{}
+	closed    bool
+}
+
+func newListenerHub() *listenerHub {
+	return &listenerHub{
+		listeners: map[string]map[Session]struct{}{},
+	}
+}
+
+// Store 已经关闭时返回false
+func (h *listenerHub) Store(sess Session) bool {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.closed {
+		return false
+	}
+
+	set, ok := h.listeners[sess.ID()]
+	if !ok {
+		set = map[Session]struct{}{}
+		h.listeners[sess.ID()] = set
+	}
+	set[sess] = struct{}{}
+	return true
+}
+
+func (h *listenerHub) Load(id string) []Session {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	set := h.listeners[id]
+	if len(set) == 0 {
+		return nil
+	}
+
+	result := make([]Session, 0, len(set))
+	for sess := range set {
+		result = append(result, sess)
+	}
+	return result
+}
+
+func (h *listenerHub) Delete(sess Session) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if set, ok := h.listeners[sess.ID()]; ok {
+		delete(set, sess)
+		if len(set) == 0 {
+			delete(h.listeners, sess.ID())
+		}
+	}
+}
+
+func (h *listenerHub) Close() {
+	h.mux.Lock()
+	listeners := h.listeners
+	h.listeners = map[string]map[Session]struct{}{}
+	h.closed = true
+	h.mux.Unlock()
+
+	for _, set := range listeners {
+		for sess := range set {
+			_ = sess.Close()
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
@@ -15,8 +16,8 @@ import (
 	"github.com/joyparty/gokit"
 	"github.com/joyparty/nodehub/cluster"
 	"github.com/joyparty/nodehub/internal/codec"
-	"github.com/joyparty/nodehub/internal/metrics"
 	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
 	"github.com/joyparty/nodehub/proto/nh"
 	"github.com/oklog/ulid/v2"
 	"github.com/quic-go/quic-go"
@@ -39,6 +40,8 @@ type quicServer struct {
 }
 
 // NewQUICServer 构造函数
+//
+// 会自动开启quicConfig.EnableDatagrams，客户端同样支持datagram时，unreliable消息通过datagram收发
 func NewQUICServer(listenAddr string, tlsConfig *tls.Config, quicConfig *quic.Config) Transporter {
 	return &quicServer{
 		listenAddr: listenAddr,
@@ -47,7 +50,7 @@ func NewQUICServer(listenAddr string, tlsConfig *tls.Config, quicConfig *quic.Co
 	}
 }
 
-// BindQUICServer 绑定QUIC服务器
+// BindQUICServer 绑定QUIC服务器，同样会自动开启datagram
 func BindQUICServer(conn net.PacketConn, tlsConfig *tls.Config, quicConfig *quic.Config) Transporter {
 	return &quicServer{
 		listenAddr: conn.LocalAddr().String(),
@@ -67,10 +70,11 @@ func (qs *quicServer) Serve(ctx context.Context) (chan Session, error) {
 		err error
 	)
 
+	quicConfig := withDatagrams(qs.quicConfig)
 	if qs.packetConn != nil {
-		l, err = quic.Listen(qs.packetConn, qs.tlsConfig, qs.quicConfig)
+		l, err = quic.Listen(qs.packetConn, qs.tlsConfig, quicConfig)
 	} else {
-		l, err = quic.ListenAddr(qs.listenAddr, qs.tlsConfig, qs.quicConfig)
+		l, err = quic.ListenAddr(qs.listenAddr, qs.tlsConfig, quicConfig)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("listen, %w", err)
@@ -102,10 +106,11 @@ func (qs *quicServer) Shutdown(_ context.Context) error {
 type quicSession struct {
 	id         string
 	conn       quic.Connection
-	streams    *quicStreams
+	streams    *quicStreams[quic.Stream]
 	msgC       chan *codec.Message
 	md         metadata.MD
 	lastRWTime gokit.ValueOf[time.Time]
+	metrics    *metrics.Metrics
 	closeOnce  sync.Once
 	done       chan struct{}
 }
@@ -114,7 +119,7 @@ func newQuicSession(conn quic.Connection) *quicSession {
 	qs := &quicSession{
 		id:         ulid.Make().String(),
 		conn:       conn,
-		streams:    newQuicStreams(),
+		streams:    newQuicStreams[quic.Stream](),
 		md:         metadata.New(nil),
 		lastRWTime: gokit.NewValueOf[time.Time](),
 		done:       make(chan struct{}),
@@ -124,6 +129,9 @@ func newQuicSession(conn quic.Connection) *quicSession {
 	qs.lastRWTime.Store(time.Now())
 
 	go qs.handleRequest()
+	if conn.ConnectionState().SupportsDatagrams {
+		go qs.handleDatagram()
+	}
 	return qs
 }
 
@@ -147,6 +155,10 @@ func (qs *quicSession) MetadataCopy() metadata.MD {
 	return qs.md.Copy()
 }
 
+func (qs *quicSession) setMetrics(m *metrics.Metrics) {
+	qs.metrics = m
+}
+
 func (qs *quicSession) LocalAddr() string {
 	return qs.conn.LocalAddr().String()
 }
@@ -162,7 +174,6 @@ func (qs *quicSession) LastRWTime() time.Time {
 func (qs *quicSession) Close() error {
 	qs.closeOnce.Do(func() {
 		close(qs.done)
-		close(qs.msgC)
 
 		qs.streams.CloseAll()
 		_ = qs.conn.CloseWithError(0, "")
@@ -220,18 +231,52 @@ func (qs *quicSession) handleRequest() {
 	}
 }
 
+// handleDatagram 接收通过datagram上行的请求，每个datagram包含一个完整的length+data数据包
+func (qs *quicSession) handleDatagram() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-qs.done
+		cancel()
+	}()
+
+	for {
+		data, err := qs.conn.ReceiveDatagram(ctx)
+		if err != nil {
+			return
+		}
+		qs.lastRWTime.Store(time.Now())
+
+		msg := codec.GetMessage()
+		if err := codec.ReadMessage(bytes.NewReader(data), msg); err != nil {
+			codec.PutMessage(msg)
+			continue
+		}
+
+		select {
+		case <-qs.done:
+			codec.PutMessage(msg)
+			return
+		case qs.msgC <- msg:
+		}
+	}
+}
+
 func (qs *quicSession) Recv(req *nh.Request) error {
 	for {
-		msg, ok := <-qs.msgC
-		if !ok {
+		var msg *codec.Message
+		select {
+		case <-qs.done:
 			return io.EOF
+		case msg = <-qs.msgC:
 		}
 
 		if msg.Len() == 0 { // ping
 			codec.PutMessage(msg)
 			continue
 		}
-		metrics.IncrPayloadSize(qs.Type(), msg.Len())
+		qs.metrics.IncrPayloadSize(qs.Type(), msg.Len())
 
 		defer codec.PutMessage(msg)
 		if err := proto.Unmarshal(msg.Bytes(), req); err != nil {
@@ -242,6 +287,15 @@ func (qs *quicSession) Recv(req *nh.Request) error {
 }
 
 func (qs *quicSession) Send(reply *nh.Reply) error {
+	if reply.GetUnreliable() && qs.conn.ConnectionState().SupportsDatagrams {
+		if ok, err := sendDatagram(reply, qs.conn.SendDatagram); ok || err != nil {
+			if err == nil {
+				qs.lastRWTime.Store(time.Now())
+			}
+			return err
+		}
+	}
+
 	s, ok := qs.streams.Pick(reply.GetServiceCode())
 	if !ok {
 		return errors.New("no available stream")
@@ -265,46 +319,52 @@ func (qs *quicSession) LogValue() slog.Value {
 	)
 }
 
-type quicStreams struct {
-	ss []quic.Stream
+// quicStream quic及webtransport的双向stream
+type quicStream interface {
+	StreamID() quic.StreamID
+	Close() error
+}
+
+type quicStreams[S quicStream] struct {
+	ss []S
 	l  sync.RWMutex
 }
 
-func newQuicStreams() *quicStreams {
-	return &quicStreams{
-		ss: []quic.Stream{},
+func newQuicStreams[S quicStream]() *quicStreams[S] {
+	return &quicStreams[S]{
+		ss: []S{},
 	}
 }
 
-func (qs *quicStreams) Len() int {
+func (qs *quicStreams[S]) Len() int {
 	qs.l.RLock()
 	defer qs.l.RUnlock()
 
 	return len(qs.ss)
 }
 
-func (qs *quicStreams) Append(s quic.Stream) {
+func (qs *quicStreams[S]) Append(s S) {
 	qs.l.Lock()
 	qs.ss = append(qs.ss, s)
 	qs.l.Unlock()
 }
 
-func (qs *quicStreams) Remove(s quic.Stream) {
+func (qs *quicStreams[S]) Remove(s S) {
 	qs.l.Lock()
 	defer qs.l.Unlock()
 
-	qs.ss = slices.DeleteFunc(qs.ss, func(v quic.Stream) bool {
+	qs.ss = slices.DeleteFunc(qs.ss, func(v S) bool {
 		return s.StreamID() == v.StreamID()
 	})
 }
 
 // Pick 根据service code分配，按id hash，确保同一个服务的下行消息都通过同一个stream下发
-func (qs *quicStreams) Pick(serviceCode int32) (quic.Stream, bool) {
+func (qs *quicStreams[S]) Pick(serviceCode int32) (s S, ok bool) {
 	qs.l.RLock()
 	defer qs.l.RUnlock()
 
 	if l := len(qs.ss); l == 0 {
-		return nil, false
+		return s, false
 	} else if l == 1 {
 		return qs.ss[0], true
 	}
@@ -312,7 +372,7 @@ func (qs *quicStreams) Pick(serviceCode int32) (quic.Stream, bool) {
 	return qs.ss[int(serviceCode)%len(qs.ss)], true
 }
 
-func (qs *quicStreams) CloseAll() {
+func (qs *quicStreams[S]) CloseAll() {
 	qs.l.Lock()
 	defer qs.l.Unlock()
 
@@ -321,6 +381,30 @@ func (qs *quicStreams) CloseAll() {
 	}
 }
 
+// withDatagrams 复制quic配置并开启datagram
+func withDatagrams(config *quic.Config) *quic.Config {
+	if config == nil {
+		return &quic.Config{EnableDatagrams: true}
+	}
+
+	config = config.Clone()
+	config.EnableDatagrams = true
+	return config
+}
+
+// sendDatagram 以datagram方式下发，超过datagram长度限制时返回false，由调用方改用stream下发
+func sendDatagram(reply *nh.Reply, sender func([]byte) error) (bool, error) {
+	err := codec.SendReply(reply, sender)
+
+	var tooLargeErr *quic.DatagramTooLargeError
+	if errors.As(err, &tooLargeErr) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("send datagram, %w", err)
+	}
+	return true, nil
+}
+
 func isUnexpectedQUICError(err error) bool {
 	if err == nil {
 		return false
@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"errors"
+
+	"github.com/joyparty/nodehub/proto/nh"
+)
+
+// replayWindowSize 请求ID滑动窗口大小，必须是64的整数倍
+const replayWindowSize = 1024
+
+// replayWindow 请求ID滑动窗口，用于检测重放请求
+//
+// quic客户端会通过多个stream发送请求，请求到达网关的顺序不一定与ID顺序一致，
+// 窗口允许范围内的请求乱序到达，只拒绝重复以及过旧的请求ID
+//
+// 请求ID按照序列号方式比较，超过uint32上限回绕之后仍然可以继续使用
+type replayWindow struct {
+	max     uint32 // 已接收的最大请求ID
+	started bool
+	bitmap  [replayWindowSize / 64]uint64
+}
+
+// accept 检查请求ID是否可以接受，可以接受的ID会被记录下来
+//
+// 客户端没有设置ID(为0)时不做检查
+func (w *replayWindow) accept(id uint32) bool {
+	if id == 0 {
+		return true
+	} else if !w.started {
+		w.started = true
+		w.max = id
+		w.set(id)
+		return true
+	}
+
+	if diff := id - w.max; diff != 0 && int32(diff) > 0 {
+		if diff >= replayWindowSize {
+			w.bitmap = [replayWindowSize / 64]uint64{}
+		} else {
+			for i := uint32(1); i <= diff; i++ {
+				w.clear(w.max + i)
+			}
+		}
+
+		w.max = id
+		w.set(id)
+		return true
+	}
+
+	if w.max-id >= replayWindowSize || w.has(id) {
+		return false
+	}
+
+	w.set(id)
+	return true
+}
+
+// acceptRequest 检查请求是否可以接受
+//
+// 可靠及不可靠方式上行的请求共用同一个窗口，切换unreliable标记不能重放已经使用过的请求ID，
+// 被拒绝的不可靠请求可能只是乱序到达的过期请求，直接丢弃即可，被拒绝的可靠请求返回错误
+func (w *replayWindow) acceptRequest(req *nh.Request) (bool, error) {
+	if w.accept(req.GetId()) {
+		return true, nil
+	} else if req.GetUnreliable() {
+		return false, nil
+	}
+	return false, errors.New("request id has already been used")
+}
+
+func (w *replayWindow) has(id uint32) bool {
+	i := id % replayWindowSize
+	return w.bitmap[i/64]&(1<<(i%64)) != 0
+}
+
+func (w *replayWindow) set(id uint32) {
+	i := id % replayWindowSize
+	w.bitmap[i/64] |= 1 << (i % 64)
+}
+
+func (w *replayWindow) clear(id uint32) {
+	i := id % replayWindowSize
+	w.bitmap[i/64] &^= 1 << (i % 64)
+}
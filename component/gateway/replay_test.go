@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"math"
+	"testing"
+
+	"github.com/joyparty/nodehub/proto/nh"
+)
+
+func TestReplayWindow(t *testing.T) {
+	type step struct {
+		id     uint32
+		accept bool
+	}
+
+	cases := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "sequential",
+			steps: []step{{1, true}, {2, true}, {3, true}},
+		},
+		{
+			name:  "zero",
+			steps: []step{{0, true}, {0, true}, {5, true}, {0, true}, {0, true}},
+		},
+		{
+			name:  "reorder",
+			steps: []step{{1, true}, {3, true}, {2, true}, {5, true}, {4, true}},
+		},
+		{
+			name:  "start with reorder",
+			steps: []step{{3, true}, {1, true}, {2, true}},
+		},
+		{
+			name:  "duplicate",
+			steps: []step{{1, true}, {3, true}, {2, true}, {3, false}, {2, false}, {1, false}},
+		},
+		{
+			name:  "too old",
+			steps: []step{{1, true}, {replayWindowSize + 1, true}, {2, true}, {1, false}},
+		},
+		{
+			name: "jump",
+			steps: []step{
+				{10, true},
+				{10 + replayWindowSize, true},
+				{10 + replayWindowSize, false},
+				{11 + replayWindowSize*3, true},
+				{12 + replayWindowSize*2, true},
+				{11 + replayWindowSize*2, false},
+			},
+		},
+		{
+			name: "wraparound",
+			steps: []step{
+				{math.MaxUint32 - 1, true},
+				{math.MaxUint32, true},
+				{1, true},
+				{math.MaxUint32, false},
+				{2, true},
+				{math.MaxUint32 - 2, true},
+				{1, false},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var w replayWindow
+			for i, s := range c.steps {
+				if got := w.accept(s.id); got != s.accept {
+					t.Fatalf("step %d, id %d, expected %v, got %v", i, s.id, s.accept, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayWindowUnreliable(t *testing.T) {
+	steps := []struct {
+		req    *nh.Request
+		accept bool
+		err    bool
+	}{
+		{req: &nh.Request{Id: 1}, accept: true},
+		// 切换unreliable标记重放已经使用过的ID
+		{req: &nh.Request{Id: 1, Unreliable: true}},
+		{req: &nh.Request{Id: 3, Unreliable: true}, accept: true},
+		{req: &nh.Request{Id: 3}, err: true},
+		// 可靠及不可靠请求之间允许乱序
+		{req: &nh.Request{Id: 2}, accept: true},
+		{req: &nh.Request{Id: 2, Unreliable: true}},
+	}
+
+	var w replayWindow
+	for i, s := range steps {
+		ok, err := w.acceptRequest(s.req)
+		if ok != s.accept {
+			t.Fatalf("step %d, expected accept %v, got %v", i, s.accept, ok)
+		} else if (err != nil) != s.err {
+			t.Fatalf("step %d, unexpected error, %v", i, err)
+		}
+	}
+}
@@ -36,6 +36,18 @@ func (st *stateTable) Store(sessID string, serviceCode int32, nodeID ulid.ULID)
 	nodes.Store(serviceCode, nodeID.String())
 }
 
+// Routes 会话的所有路由，serviceCode => nodeID
+func (st *stateTable) Routes(sessID string) map[int32]string {
+	routes := map[int32]string{}
+	if nodes, ok := st.routes.Load(sessID); ok {
+		nodes.Range(func(serviceCode int32, nodeID string) bool {
+			routes[serviceCode] = nodeID
+			return true
+		})
+	}
+	return routes
+}
+
 func (st *stateTable) Remove(sessID string, serviceCode int32) {
 	if nodes, ok := st.routes.Load(sessID); ok {
 		nodes.Delete(serviceCode)
@@ -77,3 +89,8 @@ func (st *stateTable) ReplaceNode(oldID, newID ulid.ULID) {
 func (st *stateTable) CleanSession(sessID string) {
 	st.routes.Delete(sessID)
 }
+
+// Count 路由表内的会话数量
+func (st *stateTable) Count() int {
+	return int(st.routes.Count())
+}
@@ -13,8 +13,8 @@ import (
 	"github.com/joyparty/gokit"
 	"github.com/joyparty/nodehub/cluster"
 	"github.com/joyparty/nodehub/internal/codec"
-	"github.com/joyparty/nodehub/internal/metrics"
 	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
 	"github.com/joyparty/nodehub/proto/nh"
 	"github.com/oklog/ulid/v2"
 	"google.golang.org/grpc/metadata"
@@ -92,6 +92,7 @@ type tcpSession struct {
 	conn       net.Conn
 	md         metadata.MD
 	lastRWTime gokit.ValueOf[time.Time]
+	metrics    *metrics.Metrics
 	closeOnce  sync.Once
 }
 
@@ -127,6 +128,10 @@ func (ts *tcpSession) MetadataCopy() metadata.MD {
 	return ts.md.Copy()
 }
 
+func (ts *tcpSession) setMetrics(m *metrics.Metrics) {
+	ts.metrics = m
+}
+
 func (ts *tcpSession) Recv(req *nh.Request) (err error) {
 	defer func() {
 		if errors.Is(err, net.ErrClosed) {
@@ -144,7 +149,7 @@ func (ts *tcpSession) Recv(req *nh.Request) (err error) {
 		ts.lastRWTime.Store(time.Now())
 
 		if msg.Len() > 0 {
-			metrics.IncrPayloadSize(ts.Type(), msg.Len())
+			ts.metrics.IncrPayloadSize(ts.Type(), msg.Len())
 
 			if err := proto.Unmarshal(msg.Bytes(), req); err != nil {
 				return fmt.Errorf("unmarshal request, %w", err)
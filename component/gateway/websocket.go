@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"sync"
 	"time"
 
@@ -15,14 +16,17 @@ import (
 	"github.com/joyparty/gokit"
 	"github.com/joyparty/nodehub/cluster"
 	"github.com/joyparty/nodehub/internal/codec"
-	"github.com/joyparty/nodehub/internal/metrics"
 	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
 	"github.com/joyparty/nodehub/proto/nh"
 	"github.com/oklog/ulid/v2"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 )
 
+// websocket子协议，客户端使用json格式通讯
+const wsProtocolJSON = "json"
+
 // Upgrader websocket upgrader
 var Upgrader = websocket.Upgrader{
 	HandshakeTimeout: 10 * time.Second,
@@ -35,25 +39,43 @@ var Upgrader = websocket.Upgrader{
 //
 // 客户端通过websocket方式连接网关，网关再转发请求到grpc后端服务
 type wsServer struct {
-	url      *url.URL
-	listener net.Listener
-	server   *http.Server
+	url       *url.URL
+	listener  net.Listener
+	server    *http.Server
+	jsonCodec *JSONCodec
+}
+
+// WSOption websocket服务器配置
+type WSOption func(ws *wsServer)
+
+// WithJSONCodec 允许客户端使用json格式的文本消息通讯
+//
+// 客户端连接时需要指定websocket子协议为"json"，例如浏览器内 new WebSocket(url, "json")
+func WithJSONCodec(codec *JSONCodec) WSOption {
+	return func(ws *wsServer) {
+		ws.jsonCodec = codec
+	}
 }
 
 // NewWSServer 构造函数
-func NewWSServer(listenAddr string, urlPath string) Transporter {
-	return &wsServer{
+func NewWSServer(listenAddr string, urlPath string, opts ...WSOption) Transporter {
+	ws := &wsServer{
 		url: &url.URL{
 			Scheme: "ws",
 			Host:   listenAddr,
 			Path:   urlPath,
 		},
 	}
+
+	for _, fn := range opts {
+		fn(ws)
+	}
+	return ws
 }
 
 // BindWSServer 绑定websocket服务器
-func BindWSServer(listener net.Listener, urlPath string) Transporter {
-	return &wsServer{
+func BindWSServer(listener net.Listener, urlPath string, opts ...WSOption) Transporter {
+	ws := &wsServer{
 		listener: listener,
 		url: &url.URL{
 			Scheme: "ws",
@@ -61,6 +83,11 @@ func BindWSServer(listener net.Listener, urlPath string) Transporter {
 			Path:   urlPath,
 		},
 	}
+
+	for _, fn := range opts {
+		fn(ws)
+	}
+	return ws
 }
 
 // CompleteNodeEntry 补全节点信息
@@ -119,13 +146,23 @@ func (ws *wsServer) Shutdown(ctx context.Context) error {
 }
 
 func (ws *wsServer) newSession(w http.ResponseWriter, r *http.Request) (sess Session, err error) {
-	wsConn, err := Upgrader.Upgrade(w, r, nil)
+	var (
+		header    http.Header
+		jsonCodec *JSONCodec
+	)
+	if ws.jsonCodec != nil && slices.Contains(websocket.Subprotocols(r), wsProtocolJSON) {
+		header = http.Header{"Sec-Websocket-Protocol": []string{wsProtocolJSON}}
+		jsonCodec = ws.jsonCodec
+	}
+
+	wsConn, err := Upgrader.Upgrade(w, r, header)
 	if err != nil {
 		return nil, fmt.Errorf("upgrade websocket, %w", err)
 	}
 
 	wsConn.SetReadLimit(int64(codec.MaxMessageSize))
-	return newWsSession(wsConn), nil
+
+	return newWsSession(wsConn, jsonCodec), nil
 }
 
 type wsSession struct {
@@ -133,16 +170,19 @@ type wsSession struct {
 	conn       *websocket.Conn
 	md         metadata.MD
 	lastRWTime gokit.ValueOf[time.Time]
+	metrics    *metrics.Metrics
+	jsonCodec  *JSONCodec // 不为nil时使用json格式的文本消息
 
 	writeMux  sync.Mutex
 	closeOnce sync.Once
 	done      chan struct{}
 }
 
-func newWsSession(conn *websocket.Conn) *wsSession {
+func newWsSession(conn *websocket.Conn, jsonCodec *JSONCodec) *wsSession {
 	ws := &wsSession{
 		id:         ulid.Make().String(),
 		conn:       conn,
+		jsonCodec:  jsonCodec,
 		done:       make(chan struct{}),
 		lastRWTime: gokit.NewValueOf[time.Time](),
 	}
@@ -190,6 +230,10 @@ func (ws *wsSession) MetadataCopy() metadata.MD {
 	return ws.md.Copy()
 }
 
+func (ws *wsSession) setMetrics(m *metrics.Metrics) {
+	ws.metrics = m
+}
+
 func (ws *wsSession) Recv(req *nh.Request) error {
 	for {
 		select {
@@ -210,7 +254,18 @@ func (ws *wsSession) Recv(req *nh.Request) error {
 			return err
 		}
 		ws.lastRWTime.Store(time.Now())
-		metrics.IncrPayloadSize(ws.Type(), len(message))
+		ws.metrics.IncrPayloadSize(ws.Type(), len(message))
+
+		// json模式下只处理文本消息
+		if ws.jsonCodec != nil {
+			if messageType == websocket.TextMessage {
+				if err := ws.jsonCodec.DecodeRequest(message, req); err != nil {
+					return fmt.Errorf("decode json request, %w", err)
+				}
+				return nil
+			}
+			continue
+		}
 
 		// 只处理二进制消息
 		if messageType == websocket.BinaryMessage {
@@ -223,7 +278,17 @@ func (ws *wsSession) Recv(req *nh.Request) error {
 }
 
 func (ws *wsSession) Send(reply *nh.Reply) error {
-	data, err := proto.Marshal(reply)
+	var (
+		messageType = websocket.BinaryMessage
+		data        []byte
+		err         error
+	)
+	if ws.jsonCodec != nil {
+		messageType = websocket.TextMessage
+		data, err = ws.jsonCodec.EncodeReply(reply)
+	} else {
+		data, err = proto.Marshal(reply)
+	}
 	if err != nil {
 		return fmt.Errorf("marshal response, %w", err)
 	}
@@ -232,7 +297,7 @@ func (ws *wsSession) Send(reply *nh.Reply) error {
 	defer ws.writeMux.Unlock()
 
 	ws.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
-	err = ws.conn.WriteMessage(websocket.BinaryMessage, data)
+	err = ws.conn.WriteMessage(messageType, data)
 	if err == nil {
 		ws.lastRWTime.Store(time.Now())
 	}
@@ -0,0 +1,355 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/joyparty/gokit"
+	"github.com/joyparty/nodehub/cluster"
+	"github.com/joyparty/nodehub/internal/codec"
+	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
+	"github.com/joyparty/nodehub/proto/nh"
+	"github.com/oklog/ulid/v2"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	_ Session     = &wtSession{}
+	_ Transporter = &wtServer{}
+)
+
+// wtServer WebTransport网关服务器
+//
+// 基于webtransport-go实现，浏览器可以通过WebTransport API连接网关，每个stream上使用length+data的方式分帧，
+// 与quic网关相同。同时也支持通过datagram发送请求，每个datagram内包含一个完整的length+data数据包
+type wtServer struct {
+	url        *url.URL
+	packetConn net.PacketConn
+	ownConn    bool
+
+	tlsConfig  *tls.Config
+	quicConfig *quic.Config
+	server     *webtransport.Server
+}
+
+// NewWebTransportServer 构造函数
+func NewWebTransportServer(listenAddr string, urlPath string, tlsConfig *tls.Config, quicConfig *quic.Config) Transporter {
+	return &wtServer{
+		url: &url.URL{
+			Scheme: "https",
+			Host:   listenAddr,
+			Path:   urlPath,
+		},
+		tlsConfig:  tlsConfig,
+		quicConfig: quicConfig,
+	}
+}
+
+// BindWebTransportServer 绑定WebTransport服务器
+func BindWebTransportServer(conn net.PacketConn, urlPath string, tlsConfig *tls.Config, quicConfig *quic.Config) Transporter {
+	return &wtServer{
+		url: &url.URL{
+			Scheme: "https",
+			Host:   conn.LocalAddr().String(),
+			Path:   urlPath,
+		},
+		packetConn: conn,
+		tlsConfig:  tlsConfig,
+		quicConfig: quicConfig,
+	}
+}
+
+// CompleteNodeEntry 补全节点信息
+func (ws *wtServer) CompleteNodeEntry(entry *cluster.NodeEntry) {
+	entry.Entrance = ws.url.String()
+}
+
+func (ws *wtServer) Serve(ctx context.Context) (chan Session, error) {
+	if ws.packetConn == nil {
+		conn, err := net.ListenPacket("udp", ws.url.Host)
+		if err != nil {
+			return nil, fmt.Errorf("listen, %w", err)
+		}
+		ws.packetConn = conn
+		ws.ownConn = true
+	}
+
+	ch := make(chan Session)
+
+	router := http.NewServeMux()
+	router.HandleFunc(ws.url.RequestURI(), func(w http.ResponseWriter, r *http.Request) {
+		conn, err := ws.server.Upgrade(w, r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			logger.Error("upgrade webtransport", "error", err, "remoteAddr", r.RemoteAddr)
+			return
+		}
+		sess := newWTSession(conn)
+
+		defer func() {
+			if v := recover(); v != nil {
+				// 服务器已经关闭
+				_ = sess.Close()
+			}
+		}()
+		ch <- sess
+	})
+
+	ws.server = &webtransport.Server{
+		H3: http3.Server{
+			Handler:    router,
+			TLSConfig:  ws.tlsConfig,
+			QUICConfig: ws.quicConfig,
+		},
+	}
+
+	go func() {
+		defer close(ch)
+
+		if err := ws.server.Serve(ws.packetConn); err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, quic.ErrServerClosed) {
+			logger.Error("start gateway", "error", err)
+		}
+	}()
+
+	return ch, nil
+}
+
+// Shutdown 停止WebTransport服务器
+func (ws *wtServer) Shutdown(ctx context.Context) error {
+	err := ws.server.Close()
+	if ws.ownConn {
+		_ = ws.packetConn.Close()
+	}
+	return err
+}
+
+type wtSession struct {
+	id         string
+	conn       *webtransport.Session
+	streams    *quicStreams[webtransport.Stream]
+	msgC       chan *codec.Message
+	md         metadata.MD
+	lastRWTime gokit.ValueOf[time.Time]
+	metrics    *metrics.Metrics
+	closeOnce  sync.Once
+	done       chan struct{}
+}
+
+func newWTSession(conn *webtransport.Session) *wtSession {
+	ws := &wtSession{
+		id:         ulid.Make().String(),
+		conn:       conn,
+		streams:    newQuicStreams[webtransport.Stream](),
+		md:         metadata.New(nil),
+		lastRWTime: gokit.NewValueOf[time.Time](),
+		done:       make(chan struct{}),
+
+		msgC: make(chan *codec.Message),
+	}
+	ws.lastRWTime.Store(time.Now())
+
+	go ws.handleRequest()
+	go ws.handleDatagram()
+	return ws
+}
+
+func (ws *wtSession) Type() string {
+	return "webtransport"
+}
+
+func (ws *wtSession) ID() string {
+	return ws.id
+}
+
+func (ws *wtSession) SetID(id string) {
+	ws.id = id
+}
+
+func (ws *wtSession) SetMetadata(md metadata.MD) {
+	ws.md = md
+}
+
+func (ws *wtSession) MetadataCopy() metadata.MD {
+	return ws.md.Copy()
+}
+
+func (ws *wtSession) setMetrics(m *metrics.Metrics) {
+	ws.metrics = m
+}
+
+func (ws *wtSession) LocalAddr() string {
+	return ws.conn.LocalAddr().String()
+}
+
+func (ws *wtSession) RemoteAddr() string {
+	return ws.conn.RemoteAddr().String()
+}
+
+func (ws *wtSession) LastRWTime() time.Time {
+	return ws.lastRWTime.Load()
+}
+
+func (ws *wtSession) Close() error {
+	ws.closeOnce.Do(func() {
+		close(ws.done)
+
+		ws.streams.CloseAll()
+		_ = ws.conn.CloseWithError(0, "")
+	})
+	return nil
+}
+
+// handleRequest 接收客户端打开的stream，session结束时关闭
+func (ws *wtSession) handleRequest() {
+	for {
+		s, err := ws.conn.AcceptStream(context.Background())
+		if err != nil {
+			if isUnexpectedWTError(err) {
+				logger.Error("accept webtransport stream", "error", err, "session", ws)
+			}
+
+			_ = ws.Close()
+			return
+		}
+		ws.streams.Append(s)
+
+		go func() (err error) {
+			defer func() {
+				if isUnexpectedWTError(err) {
+					logger.Error("handle webtransport stream", "error", err, "session", ws)
+				}
+
+				s.CancelRead(0)
+				ws.streams.Remove(s)
+			}()
+
+			for {
+				msg := codec.GetMessage()
+				if err := codec.ReadMessage(s, msg); err != nil {
+					codec.PutMessage(msg)
+					return err
+				}
+				ws.lastRWTime.Store(time.Now())
+
+				select {
+				case <-ws.done:
+					codec.PutMessage(msg)
+					return
+				case ws.msgC <- msg:
+				}
+			}
+		}()
+	}
+}
+
+// handleDatagram 接收通过datagram上行的请求，每个datagram包含一个完整的length+data数据包
+func (ws *wtSession) handleDatagram() {
+	ctx := ws.conn.Context()
+
+	for {
+		data, err := ws.conn.ReceiveDatagram(ctx)
+		if err != nil {
+			return
+		}
+		ws.lastRWTime.Store(time.Now())
+
+		msg := codec.GetMessage()
+		if err := codec.ReadMessage(bytes.NewReader(data), msg); err != nil {
+			codec.PutMessage(msg)
+			continue
+		}
+
+		select {
+		case <-ws.done:
+			codec.PutMessage(msg)
+			return
+		case ws.msgC <- msg:
+		}
+	}
+}
+
+func (ws *wtSession) Recv(req *nh.Request) error {
+	for {
+		var msg *codec.Message
+		select {
+		case <-ws.done:
+			return io.EOF
+		case msg = <-ws.msgC:
+		}
+
+		if msg.Len() == 0 { // ping
+			codec.PutMessage(msg)
+			continue
+		}
+		ws.metrics.IncrPayloadSize(ws.Type(), msg.Len())
+
+		defer codec.PutMessage(msg)
+		if err := proto.Unmarshal(msg.Bytes(), req); err != nil {
+			return fmt.Errorf("unmarshal request, %w", err)
+		}
+		return nil
+	}
+}
+
+func (ws *wtSession) Send(reply *nh.Reply) error {
+	// 建立session时已经确认客户端支持datagram
+	if reply.GetUnreliable() {
+		if ok, err := sendDatagram(reply, ws.conn.SendDatagram); ok || err != nil {
+			if err == nil {
+				ws.lastRWTime.Store(time.Now())
+			}
+			return err
+		}
+	}
+
+	s, ok := ws.streams.Pick(reply.GetServiceCode())
+	if !ok {
+		return errors.New("no available stream")
+	}
+
+	return codec.SendReply(reply, func(data []byte) error {
+		_ = s.SetWriteDeadline(time.Now().Add(WriteTimeout))
+		_, err := s.Write(data)
+		if err == nil {
+			ws.lastRWTime.Store(time.Now())
+		}
+		return err
+	})
+}
+
+func (ws *wtSession) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", ws.id),
+		slog.String("type", "webtransport"),
+		slog.String("addr", ws.RemoteAddr()),
+	)
+}
+
+func isUnexpectedWTError(err error) bool {
+	var sessErr *webtransport.SessionError
+	if errors.As(err, &sessErr) {
+		return sessErr.ErrorCode > 0
+	}
+
+	var streamErr *webtransport.StreamError
+	if errors.As(err, &streamErr) {
+		return streamErr.ErrorCode > 0
+	}
+
+	return isUnexpectedQUICError(err) && !errors.Is(err, io.EOF)
+}
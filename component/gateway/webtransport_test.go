@@ -0,0 +1,173 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/joyparty/nodehub/internal/codec"
+	"github.com/joyparty/nodehub/proto/nh"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/webtransport-go"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWebTransport(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	server := BindWebTransportServer(conn, "/nodehub", newTestTLSConfig(t), nil)
+	sessC, err := server.Serve(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown(ctx)
+
+	dialer := webtransport.Dialer{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		QUICConfig:      &quic.Config{EnableDatagrams: true},
+	}
+	defer dialer.Close()
+
+	_, client, err := dialer.Dial(ctx, "https://"+conn.LocalAddr().String()+"/nodehub", nil)
+	if err != nil {
+		t.Fatalf("dial, %v", err)
+	}
+
+	var sess Session
+	select {
+	case sess = <-sessC:
+	case <-ctx.Done():
+		t.Fatal("session not accepted")
+	}
+
+	// stream
+	stream, err := client.OpenStreamSync(ctx)
+	if err != nil {
+		t.Fatalf("open stream, %v", err)
+	}
+	if err := codec.SendBytes(mustMarshal(t, &nh.Request{Id: 1, ServiceCode: 1, Method: "Stream"}), writeTo(stream)); err != nil {
+		t.Fatalf("send request, %v", err)
+	}
+	if req := recvRequest(t, sess); req.GetId() != 1 || req.GetMethod() != "Stream" {
+		t.Fatalf("unexpected stream request, %v", req)
+	}
+
+	if err := sess.Send(&nh.Reply{RequestId: 1, ServiceCode: 1, Code: 10}); err != nil {
+		t.Fatalf("send reply, %v", err)
+	}
+	msg := codec.GetMessage()
+	defer codec.PutMessage(msg)
+	if err := codec.ReadMessage(stream, msg); err != nil {
+		t.Fatalf("read reply, %v", err)
+	} else if reply := mustUnmarshalReply(t, msg.Bytes()); reply.GetRequestId() != 1 || reply.GetCode() != 10 {
+		t.Fatalf("unexpected stream reply, %v", reply)
+	}
+
+	// datagram
+	if err := codec.SendBytes(mustMarshal(t, &nh.Request{Id: 2, ServiceCode: 1, Method: "Datagram", Unreliable: true}), client.SendDatagram); err != nil {
+		t.Fatalf("send datagram, %v", err)
+	}
+	if req := recvRequest(t, sess); req.GetId() != 2 || !req.GetUnreliable() {
+		t.Fatalf("unexpected datagram request, %v", req)
+	}
+
+	if err := sess.Send(&nh.Reply{RequestId: 2, ServiceCode: 1, Code: 20, Unreliable: true}); err != nil {
+		t.Fatalf("send unreliable reply, %v", err)
+	}
+	data, err := client.ReceiveDatagram(ctx)
+	if err != nil {
+		t.Fatalf("receive datagram, %v", err)
+	}
+	msg.Reset()
+	if err := codec.ReadMessage(bytes.NewReader(data), msg); err != nil {
+		t.Fatalf("read datagram, %v", err)
+	} else if reply := mustUnmarshalReply(t, msg.Bytes()); reply.GetRequestId() != 2 || reply.GetCode() != 20 {
+		t.Fatalf("unexpected datagram reply, %v", reply)
+	}
+
+	// 客户端关闭session
+	_ = client.CloseWithError(0, "")
+	if err := sess.Recv(&nh.Request{}); !errors.Is(err, io.EOF) {
+		t.Fatalf("session not closed, %v", err)
+	}
+}
+
+func recvRequest(t *testing.T, sess Session) *nh.Request {
+	t.Helper()
+
+	req := &nh.Request{}
+	if err := sess.Recv(req); err != nil {
+		t.Fatalf("recv request, %v", err)
+	}
+	return req
+}
+
+func writeTo(w io.Writer) func([]byte) error {
+	return func(data []byte) error {
+		_, err := w.Write(data)
+		return err
+	}
+}
+
+func mustMarshal(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+
+	data, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func mustUnmarshalReply(t *testing.T, data []byte) *nh.Reply {
+	t.Helper()
+
+	reply := &nh.Reply{}
+	if err := proto.Unmarshal(data, reply); err != nil {
+		t.Fatal(err)
+	}
+	return reply
+}
+
+func newTestTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "nodehub"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+}
@@ -2,19 +2,21 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/joyparty/nodehub/cluster"
-	in "github.com/joyparty/nodehub/internal/metrics"
 	"github.com/joyparty/nodehub/logger"
+	nhmetrics "github.com/joyparty/nodehub/metrics"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Server is a prometheus metrics server
 type Server struct {
-	addr string
-	s    *http.Server
+	addr    string
+	s       *http.Server
+	metrics *nhmetrics.Metrics
 }
 
 // NewServer 构造函数
@@ -34,9 +36,19 @@ func (s *Server) CompleteNodeEntry(entry *cluster.NodeEntry) {
 	entry.Metrics = fmt.Sprintf("http://%s/metrics", s.addr)
 }
 
+// SetMetrics 设置需要输出的metrics，必须在启动之前设置
+//
+// 通过nodehub.Node.AddComponent()添加时，会自动设置为服务注册表的metrics
+func (s *Server) SetMetrics(m *nhmetrics.Metrics) {
+	s.metrics = m
+}
+
 // Start implements nodehub.Component interface.
 func (s *Server) Start(ctx context.Context) error {
-	reg := in.Init()
+	if s.metrics == nil {
+		return errors.New("metrics not set, use cluster.Registry.Metrics()")
+	}
+	reg := s.metrics.Registry()
 
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
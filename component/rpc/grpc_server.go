@@ -5,11 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/joyparty/nodehub/cluster"
 	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
+	"github.com/joyparty/nodehub/tracing"
 	"github.com/samber/lo"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
 )
 
 const (
@@ -27,25 +34,36 @@ type GRPCServer struct {
 	listener   net.Listener
 	server     *grpc.Server
 	services   map[int32]cluster.GRPCServiceDesc
+	replyCodes map[string]int32
+	health     *health.Server
+	reflection bool
+	metrics    *metrics.Metrics
 }
 
 // NewGRPCServer 构造函数
 func NewGRPCServer(listenAddr string, opts ...grpc.ServerOption) *GRPCServer {
-	return &GRPCServer{
-		listenAddr: listenAddr,
-		server:     grpc.NewServer(opts...),
-		services:   make(map[int32]cluster.GRPCServiceDesc),
-	}
+	return newGRPCServer(listenAddr, nil, opts)
 }
 
 // BindGRPCServer 绑定grpc服务器
 func BindGRPCServer(listener net.Listener, opts ...grpc.ServerOption) *GRPCServer {
-	return &GRPCServer{
-		listenAddr: listener.Addr().String(),
+	return newGRPCServer(listener.Addr().String(), listener, opts)
+}
+
+func newGRPCServer(listenAddr string, listener net.Listener, opts []grpc.ServerOption) *GRPCServer {
+	gs := &GRPCServer{
+		listenAddr: listenAddr,
 		listener:   listener,
-		server:     grpc.NewServer(opts...),
 		services:   make(map[int32]cluster.GRPCServiceDesc),
+		replyCodes: make(map[string]int32),
 	}
+
+	opts = append(opts[:len(opts):len(opts)],
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor(), traceUnary, gs.observeUnary, gs.packReply),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor(), traceStream, gs.observeStream),
+	)
+	gs.server = grpc.NewServer(opts...)
+	return gs
 }
 
 // RegisterService 注册服务
@@ -71,6 +89,48 @@ func (gs *GRPCServer) RegisterService(code int32, desc grpc.ServiceDesc, impl an
 	return nil
 }
 
+// AddReplyCodes 添加方法返回值对应的nodehub.Reply.code，这些方法的返回值会被自动打包为nodehub.Reply
+//
+// 效果等同于PackReply拦截器，需要在Start之前调用
+func (gs *GRPCServer) AddReplyCodes(replyCodes map[string]int32) {
+	for method, code := range replyCodes {
+		gs.replyCodes[method] = code
+	}
+}
+
+func (gs *GRPCServer) packReply(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return packReply(ctx, gs.replyCodes, req, info, handler)
+}
+
+// SetMetrics 设置请求统计使用的metrics，没有设置时不做统计
+//
+// 通过nodehub.Node.AddComponent()添加时，会自动设置为服务注册表的metrics
+func (gs *GRPCServer) SetMetrics(m *metrics.Metrics) {
+	gs.metrics = m
+}
+
+func (gs *GRPCServer) observeUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	start := time.Now()
+	resp, err = handler(ctx, req)
+	gs.metrics.IncrServerRequests(info.FullMethod, err, time.Since(start))
+	return
+}
+
+func (gs *GRPCServer) observeStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	gs.metrics.IncrServerRequests(info.FullMethod, err, time.Since(start))
+	return err
+}
+
+// EnableReflection 启动时注册gRPC反射服务
+//
+// 网关json模式需要通过反射服务获取protobuf描述信息，反射服务会暴露所有的protobuf定义，
+// 只在gRPC端口不对外开放时开启
+func (gs *GRPCServer) EnableReflection() {
+	gs.reflection = true
+}
+
 // Name 服务名称
 func (gs *GRPCServer) Name() string {
 	return "grpc"
@@ -86,6 +146,19 @@ func (gs *GRPCServer) Start(ctx context.Context) error {
 		gs.listener = l
 	}
 
+	// 网关json模式通过反射服务获取protobuf描述信息，已经自行注册了反射服务的不重复注册
+	if gs.reflection {
+		if _, ok := gs.server.GetServiceInfo()[reflectionpb.ServerReflection_ServiceDesc.ServiceName]; !ok {
+			reflection.Register(gs.server)
+		}
+	}
+
+	// 服务注册表的健康检查，已经自行注册了健康检查服务的不覆盖
+	if _, ok := gs.server.GetServiceInfo()[healthpb.Health_ServiceDesc.ServiceName]; !ok {
+		gs.health = health.NewServer()
+		healthpb.RegisterHealthServer(gs.server, gs.health)
+	}
+
 	go func() {
 		if err := gs.server.Serve(gs.listener); err != nil {
 			logger.Error("start grpc", "error", err)
@@ -99,6 +172,9 @@ func (gs *GRPCServer) Start(ctx context.Context) error {
 
 // Stop 停止服务
 func (gs *GRPCServer) Stop(ctx context.Context) {
+	if gs.health != nil {
+		gs.health.Shutdown()
+	}
 	gs.server.GracefulStop()
 
 	if err := gs.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
@@ -157,3 +233,47 @@ func WithWeight(weight int) Option {
 		return desc
 	}
 }
+
+// WithTimeout 设置服务超时时间，网关调用这个服务时会代替全局的请求超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(desc cluster.GRPCServiceDesc) cluster.GRPCServiceDesc {
+		desc.Timeout = timeout
+		return desc
+	}
+}
+
+// WithMethodTimeout 设置方法超时时间，会代替服务超时时间
+func WithMethodTimeout(method string, timeout time.Duration) Option {
+	return func(desc cluster.GRPCServiceDesc) cluster.GRPCServiceDesc {
+		timeouts := make(map[string]time.Duration, len(desc.Timeouts)+1)
+		for k, v := range desc.Timeouts {
+			timeouts[k] = v
+		}
+		timeouts[method] = timeout
+
+		desc.Timeouts = timeouts
+		return desc
+	}
+}
+
+// WithRetry 设置服务默认的重试策略，网关调用无状态服务失败时会换一个节点重试，只应该用于幂等的服务
+func WithRetry(policy cluster.RetryPolicy) Option {
+	return func(desc cluster.GRPCServiceDesc) cluster.GRPCServiceDesc {
+		desc.Retry = &policy
+		return desc
+	}
+}
+
+// WithMethodRetry 设置方法的重试策略，会代替服务默认的重试策略，只应该用于幂等的方法
+func WithMethodRetry(method string, policy cluster.RetryPolicy) Option {
+	return func(desc cluster.GRPCServiceDesc) cluster.GRPCServiceDesc {
+		retries := make(map[string]cluster.RetryPolicy, len(desc.MethodRetries)+1)
+		for k, v := range desc.MethodRetries {
+			retries[k] = v
+		}
+		retries[method] = policy
+
+		desc.MethodRetries = retries
+		return desc
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/joyparty/nodehub/logger"
 	"github.com/joyparty/nodehub/proto/nh"
 	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
@@ -41,6 +43,39 @@ func logRequest(ctx context.Context, l logger.Logger, method string) func(err er
 	}
 }
 
+// annotateSpan 把网关传递的会话信息记录到span
+func annotateSpan(ctx context.Context) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return
+	}
+
+	for key, attr := range map[string]string{
+		MDSessID:        "nodehub.session.id",
+		MDTransactionID: "nodehub.transaction.id",
+		MDGateway:       "nodehub.gateway",
+	} {
+		if v := md.Get(key); len(v) > 0 {
+			span.SetAttributes(attribute.String(attr, v[0]))
+		}
+	}
+}
+
+func traceUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	annotateSpan(ctx)
+	return handler(ctx, req)
+}
+
+func traceStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	annotateSpan(ss.Context())
+	return handler(srv, ss)
+}
+
 // LogUnary 打印unary请求日志
 func LogUnary(l logger.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
@@ -55,20 +90,29 @@ func PackReply(replyCodes ...map[string]int32) grpc.UnaryServerInterceptor {
 	codes := lo.Assign(replyCodes...)
 
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
-		resp, err = handler(ctx, req)
-		if err != nil {
-			return
-		}
+		return packReply(ctx, codes, req, info, handler)
+	}
+}
 
-		if code, ok := codes[info.FullMethod]; ok {
-			var reply *nh.Reply
-			reply, err = nh.NewReply(code, resp.(proto.Message))
-			if err != nil {
-				err = fmt.Errorf("pack nodehub.Reply, %w", err)
-			}
-			resp = reply
-		}
+func packReply(ctx context.Context, codes map[string]int32, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	resp, err = handler(ctx, req)
+	if err != nil {
+		return
+	}
 
+	code, ok := codes[info.FullMethod]
+	if !ok {
 		return
+	} else if _, packed := resp.(*nh.Reply); packed {
+		// 已经被其它PackReply拦截器打包过
+		return
+	}
+
+	var reply *nh.Reply
+	reply, err = nh.NewReply(code, resp.(proto.Message))
+	if err != nil {
+		err = fmt.Errorf("pack nodehub.Reply, %w", err)
 	}
+	resp = reply
+	return
 }
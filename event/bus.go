@@ -7,14 +7,18 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/joyparty/nodehub/internal/metrics"
 	"github.com/joyparty/nodehub/internal/mq"
 	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
+	"github.com/joyparty/nodehub/tracing"
 	"github.com/nats-io/nats.go"
 	"github.com/reactivex/rxgo/v2"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Queue 消息队列
@@ -53,7 +57,8 @@ func NewRedisBus(client *redis.Client, options ...func(*Options)) *Bus {
 
 // Bus 事件总线
 type Bus struct {
-	queue mq.Queue
+	queue   mq.Queue
+	metrics atomic.Pointer[metrics.Metrics]
 
 	observeOnce sync.Once
 	observable  rxgo.Observable // type: payload
@@ -64,12 +69,19 @@ type Bus struct {
 // Example:
 //
 //	bus.Publish(ctx, event.UserConnected{...})
-func (bus *Bus) Publish(ctx context.Context, event any) error {
+func (bus *Bus) Publish(ctx context.Context, event any) (err error) {
 	p, err := newPayload(event)
 	if err != nil {
 		panic(fmt.Errorf("publish event, %w", err))
 	}
 
+	ctx, span := tracing.Start(ctx, "event.publish "+p.Type, trace.SpanKindProducer,
+		attribute.String("messaging.destination.name", bus.queue.Topic()),
+		attribute.String("nodehub.event", p.Type),
+	)
+	defer func() { tracing.End(span, err) }()
+	p.Trace = tracing.Inject(ctx)
+
 	data, err := json.Marshal(p)
 	if err != nil {
 		return err
@@ -125,6 +137,12 @@ func (bus *Bus) Subscribe(ctx context.Context, handler any) {
 					return
 				}
 
+				_, span := tracing.Start(tracing.Extract(ctx, p.Trace), "event.consume "+p.Type, trace.SpanKindConsumer,
+					attribute.String("messaging.destination.name", bus.queue.Topic()),
+					attribute.String("nodehub.event", p.Type),
+				)
+				defer span.End()
+
 				fn.Call([]reflect.Value{
 					ev.Elem(),
 					reflect.ValueOf(p.GetTime()),
@@ -156,13 +174,20 @@ func (bus *Bus) observe() {
 				} else {
 					next <- rxgo.Of(p)
 
-					metrics.IncrMessageQueue(bus.queue.Topic(), time.Since(p.GetTime()))
+					bus.metrics.Load().IncrMessageQueue(bus.queue.Topic(), time.Since(p.GetTime()))
 				}
 			}
 		}}, rxgo.WithErrorStrategy(rxgo.ContinueOnError))
 	})
 }
 
+// SetMetrics 设置消息队列统计使用的metrics，没有设置时不做统计
+//
+// 通过gateway.WithEventBus()配置给网关时，会自动设置为网关服务注册表的metrics
+func (bus *Bus) SetMetrics(m *metrics.Metrics) {
+	bus.metrics.Store(m)
+}
+
 // Close 关闭事件总线连接
 func (bus *Bus) Close() {
 	bus.queue.Close()
@@ -39,6 +39,8 @@ type payload struct {
 	Time   int64  `json:"t"`
 	Type   string `json:"ty"`
 	Detail []byte `json:"d"`
+	// 链路追踪信息
+	Trace map[string]string `json:"tr,omitempty"`
 }
 
 func (p payload) GetTime() time.Time {
@@ -4,8 +4,15 @@
 package roompb
 
 import (
+	context "context"
+	fmt "fmt"
+	client "github.com/joyparty/nodehub/component/gateway/client"
+	rpc "github.com/joyparty/nodehub/component/rpc"
+	multicast "github.com/joyparty/nodehub/multicast"
 	nh "github.com/joyparty/nodehub/proto/nh"
 	proto "google.golang.org/protobuf/proto"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	time "time"
 )
 
 // Room_MethodReplyCodes 每个grpc方法返回值对应的nodehub.Reply.code
@@ -23,3 +30,100 @@ func PackNews(msg *News) (*nh.Reply, error) {
 		Data:        data,
 	}, nil
 }
+
+// PushNews 通过消息队列向多个会话推送News消息
+func PushNews(ctx context.Context, publisher multicast.Publisher, receivers []string, msg *News) error {
+	reply, err := PackNews(msg)
+	if err != nil {
+		return fmt.Errorf("pack reply, %w", err)
+	}
+	return publisher.Publish(ctx, nh.NewMulticast(receivers, reply))
+}
+
+// SendNews 通过网关grpc接口向会话推送News消息，会话不存在时返回false
+func SendNews(ctx context.Context, gateway nh.GatewayClient, sessionID string, msg *News) (bool, error) {
+	reply, err := PackNews(msg)
+	if err != nil {
+		return false, fmt.Errorf("pack reply, %w", err)
+	}
+
+	resp, err := gateway.SendReply(ctx, &nh.SendReplyRequest{
+		SessionId: sessionID,
+		Reply:     reply,
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetSuccess(), nil
+}
+
+// RoomGatewayClient 通过网关调用Room服务
+type RoomGatewayClient struct {
+	c *client.Client
+}
+
+// NewRoomGatewayClient 构造函数
+func NewRoomGatewayClient(c *client.Client) *RoomGatewayClient {
+	return &RoomGatewayClient{c: c}
+}
+
+func (x *RoomGatewayClient) Join(ctx context.Context, in *JoinRequest, opts ...client.CallOption) (*emptypb.Empty, error) {
+	reply, err := x.c.InvokeReply(ctx, 1, "Join", in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(emptypb.Empty)
+	if err := proto.Unmarshal(reply.GetData(), out); err != nil {
+		return nil, fmt.Errorf("unmarshal reply message, %w", err)
+	}
+	return out, nil
+}
+
+func (x *RoomGatewayClient) Say(ctx context.Context, in *SayRequest, opts ...client.CallOption) (*emptypb.Empty, error) {
+	reply, err := x.c.InvokeReply(ctx, 1, "Say", in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(emptypb.Empty)
+	if err := proto.Unmarshal(reply.GetData(), out); err != nil {
+		return nil, fmt.Errorf("unmarshal reply message, %w", err)
+	}
+	return out, nil
+}
+
+func (x *RoomGatewayClient) Leave(ctx context.Context, in *emptypb.Empty, opts ...client.CallOption) (*emptypb.Empty, error) {
+	reply, err := x.c.InvokeReply(ctx, 1, "Leave", in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(emptypb.Empty)
+	if err := proto.Unmarshal(reply.GetData(), out); err != nil {
+		return nil, fmt.Errorf("unmarshal reply message, %w", err)
+	}
+	return out, nil
+}
+
+// OnNews 注册News消息处理器
+func OnNews(c *client.Client, handler func(requestID uint32, msg *News)) {
+	c.OnReceive(1, 1, handler)
+}
+
+// RegisterRoom 注册Room服务，proto内配置的路由选项及返回值代码会自动生效，opts可以覆盖proto内的配置
+func RegisterRoom(gs *rpc.GRPCServer, impl RoomServer, opts ...rpc.Option) error {
+	options := []rpc.Option{
+		rpc.WithPublic(),
+		rpc.WithStateful(),
+		rpc.WithAllocation("auto"),
+		rpc.WithMethodTimeout("Say", 3*time.Second),
+	}
+
+	if err := gs.RegisterService(1, Room_ServiceDesc, impl, append(options, opts...)...); err != nil {
+		return err
+	}
+
+	gs.AddReplyCodes(Room_MethodReplyCodes)
+	return nil
+}
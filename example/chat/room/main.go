@@ -13,7 +13,6 @@ import (
 	"github.com/joyparty/nodehub/cluster"
 	"github.com/joyparty/nodehub/component/rpc"
 	"github.com/joyparty/nodehub/event"
-	"github.com/joyparty/nodehub/example/chat/proto/clusterpb"
 	"github.com/joyparty/nodehub/example/chat/proto/roompb"
 	"github.com/joyparty/nodehub/logger"
 	"github.com/joyparty/nodehub/multicast"
@@ -84,15 +83,8 @@ func newGRPCServer() (*rpc.GRPCServer, error) {
 	})
 
 	server := rpc.NewGRPCServer(listenAddr, grpc.UnaryInterceptor(rpc.LogUnary(slog.Default())))
-	err := server.RegisterService(
-		int32(clusterpb.Services_ROOM),
-		roompb.Room_ServiceDesc,
-		service,
-		rpc.WithPublic(),
-		rpc.WithStateful(),
-		rpc.WithAllocation(cluster.AutoAllocate),
-	)
-	if err != nil {
+	// 服务代码及路由选项在proto内配置
+	if err := roompb.RegisterRoom(server, service); err != nil {
 		return nil, err
 	}
 
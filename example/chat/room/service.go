@@ -6,11 +6,9 @@ import (
 
 	"github.com/joyparty/gokit"
 	"github.com/joyparty/nodehub/component/rpc"
-	"github.com/joyparty/nodehub/example/chat/proto/clusterpb"
 	"github.com/joyparty/nodehub/example/chat/proto/roompb"
 	"github.com/joyparty/nodehub/logger"
 	"github.com/joyparty/nodehub/multicast"
-	"github.com/joyparty/nodehub/proto/nh"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -76,29 +74,21 @@ func (rs *roomService) Say(ctx context.Context, req *roompb.SayRequest) (*emptyp
 }
 
 func (rs *roomService) boardcast(news *roompb.News) {
-	response, _ := nh.NewReply(int32(roompb.ReplyCode_NEWS), news)
-	response.ServiceCode = int32(clusterpb.Services_ROOM)
-
 	receiver := []string{}
 	rs.members.Range(func(id, name string) bool {
 		receiver = append(receiver, id)
 		return true
 	})
 
-	notify := nh.NewMulticast(receiver, response)
-	if err := rs.publisher.Publish(context.Background(), notify); err != nil {
+	if err := roompb.PushNews(context.Background(), rs.publisher, receiver, news); err != nil {
 		logger.Error("publish notification", "error", err)
 	}
 }
 
 func (rs *roomService) unicast(toName string, news *roompb.News) {
-	response, _ := nh.NewReply(int32(roompb.ReplyCode_NEWS), news)
-	response.ServiceCode = int32(clusterpb.Services_ROOM)
-
 	rs.members.Range(func(id, name string) bool {
 		if name == toName {
-			notify := nh.NewMulticast([]string{id}, response)
-			if err := rs.publisher.Publish(context.Background(), notify); err != nil {
+			if err := roompb.PushNews(context.Background(), rs.publisher, []string{id}, news); err != nil {
 				logger.Error("publish notification", "error", err)
 			}
 			return false
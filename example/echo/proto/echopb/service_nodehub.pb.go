@@ -4,7 +4,11 @@
 package echopb
 
 import (
+	context "context"
+	fmt "fmt"
+	client "github.com/joyparty/nodehub/component/gateway/client"
 	nh "github.com/joyparty/nodehub/proto/nh"
+	proto "google.golang.org/protobuf/proto"
 )
 
 func init() {
@@ -15,3 +19,30 @@ func init() {
 var Echo_MethodReplyCodes = map[string]int32{
 	"/echo.Echo/Send": 1,
 }
+
+// EchoGatewayClient 通过网关调用Echo服务
+type EchoGatewayClient struct {
+	c *client.Client
+}
+
+// NewEchoGatewayClient 构造函数
+func NewEchoGatewayClient(c *client.Client) *EchoGatewayClient {
+	return &EchoGatewayClient{c: c}
+}
+
+func (x *EchoGatewayClient) Send(ctx context.Context, in *Msg, opts ...client.CallOption) (*Msg, error) {
+	reply, err := x.c.InvokeReply(ctx, 2, "Send", in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.GetCode() != 1 {
+		return nil, fmt.Errorf("unexpected reply code %d, expect 1", reply.GetCode())
+	}
+
+	out := new(Msg)
+	if err := proto.Unmarshal(reply.GetData(), out); err != nil {
+		return nil, fmt.Errorf("unmarshal reply message, %w", err)
+	}
+	return out, nil
+}
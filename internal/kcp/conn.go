@@ -0,0 +1,547 @@
+package kcp
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/joyparty/gokit"
+)
+
+var (
+	// ErrDeadLink 数据包多次重传都没有得到确认，连接已失效
+	ErrDeadLink = errors.New("kcp: dead link")
+
+	refTime = time.Now()
+)
+
+func currentMs() uint32 {
+	return uint32(time.Since(refTime) / time.Millisecond)
+}
+
+// Config KCP传输参数
+//
+// 参数含义参考 https://github.com/skywind3000/kcp/blob/master/README.en.md#protocol-configuration
+type Config struct {
+	// 是否启用nodelay模式，启用后最小RTO为30ms
+	NoDelay bool
+
+	// 内部状态更新间隔，单位毫秒，取值范围10-5000
+	Interval int
+
+	// 快速重传，跳过多少次ACK之后立即重传，0表示关闭快速重传
+	Resend int
+
+	// 是否关闭拥塞控制
+	NoCongestion bool
+
+	// 发送窗口大小，单位为数据包
+	SendWindow int
+
+	// 接收窗口大小，单位为数据包
+	RecvWindow int
+
+	// 最大传输单元，不能超过底层网络的MTU
+	MTU int
+
+	// 服务器端最大连接数，达到上限后不再建立新连接，0表示不限制
+	MaxSessions int
+}
+
+// DefaultConfig 默认配置，极速模式
+func DefaultConfig() *Config {
+	return &Config{
+		NoDelay:      true,
+		Interval:     10,
+		Resend:       2,
+		NoCongestion: true,
+		SendWindow:   128,
+		RecvWindow:   128,
+		MTU:          1350,
+		MaxSessions:  10000,
+	}
+}
+
+func (c *Config) apply(k *kcp) {
+	nodelay, nc := 0, 0
+	if c.NoDelay {
+		nodelay = 1
+	}
+	if c.NoCongestion {
+		nc = 1
+	}
+
+	interval := c.Interval
+	if interval <= 0 {
+		interval = intervalDef
+	}
+
+	k.NoDelay(nodelay, interval, c.Resend, nc)
+	k.WndSize(c.SendWindow, c.RecvWindow)
+	if c.MTU > 0 {
+		k.SetMTU(c.MTU)
+	}
+
+	// 网关使用length+data的方式自行分帧，所以使用流模式
+	k.stream = 1
+}
+
+// Conn KCP连接，实现了net.Conn接口
+type Conn struct {
+	mux sync.Mutex
+	kcp *kcp
+
+	conn     net.PacketConn
+	remote   net.Addr
+	listener *Listener // 服务器端连接所属的监听器
+	ownConn  bool      // 关闭时是否同时关闭底层连接
+
+	pending []byte // 上次读取剩余的数据
+
+	readDeadline  gokit.ValueOf[time.Time]
+	writeDeadline gokit.ValueOf[time.Time]
+
+	readC     chan struct{}
+	writeC    chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+func newConn(conv uint32, conn net.PacketConn, remote net.Addr, config *Config) *Conn {
+	c := &Conn{
+		conn:          conn,
+		remote:        remote,
+		readDeadline:  gokit.NewValueOf[time.Time](),
+		writeDeadline: gokit.NewValueOf[time.Time](),
+		readC:         make(chan struct{}, 1),
+		writeC:        make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	c.readDeadline.Store(time.Time{})
+	c.writeDeadline.Store(time.Time{})
+
+	c.kcp = newKCP(conv, func(data []byte) {
+		_, _ = c.conn.WriteTo(data, c.remote)
+	})
+	config.apply(c.kcp)
+
+	go c.update()
+	return c
+}
+
+// Dial 连接到KCP服务器
+func Dial(addr string, config *Config) (*Conn, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("resolve address, %w", err)
+	}
+
+	udpConn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, fmt.Errorf("listen udp, %w", err)
+	}
+
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		_ = udpConn.Close()
+		return nil, fmt.Errorf("generate conv, %w", err)
+	}
+
+	c := newConn(binary.LittleEndian.Uint32(b[:]), udpConn, raddr, config)
+	c.ownConn = true
+
+	go c.readLoop()
+	return c, nil
+}
+
+// 客户端连接自行读取底层数据包
+func (c *Conn) readLoop() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, addr, err := c.conn.ReadFrom(buf)
+		if err != nil {
+			c.closeWithError(err)
+			return
+		}
+
+		if addr.String() == c.remote.String() {
+			c.input(buf[:n])
+		}
+	}
+}
+
+// 定时驱动协议状态更新
+func (c *Conn) update() {
+	ticker := time.NewTicker(time.Duration(c.kcp.interval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.mux.Lock()
+			c.kcp.Update(currentMs())
+			dead := c.kcp.dead
+			writable := c.kcp.WaitSnd() < int(c.kcp.sndWnd)*2
+			c.mux.Unlock()
+
+			if dead {
+				c.closeWithError(ErrDeadLink)
+				return
+			} else if writable {
+				notify(c.writeC)
+			}
+		}
+	}
+}
+
+func (c *Conn) input(data []byte) {
+	c.mux.Lock()
+	c.kcp.current = currentMs()
+	ret := c.kcp.Input(data)
+	readable := c.kcp.PeekSize() >= 0
+	writable := c.kcp.WaitSnd() < int(c.kcp.sndWnd)*2
+	c.mux.Unlock()
+
+	if ret < 0 {
+		return
+	}
+
+	if readable {
+		notify(c.readC)
+	}
+	if writable {
+		notify(c.writeC)
+	}
+}
+
+// Read implements net.Conn
+func (c *Conn) Read(b []byte) (int, error) {
+	for {
+		c.mux.Lock()
+		if len(c.pending) > 0 {
+			n := copy(b, c.pending)
+			c.pending = c.pending[n:]
+			c.mux.Unlock()
+			return n, nil
+		}
+
+		if size := c.kcp.PeekSize(); size > 0 {
+			if len(b) >= size {
+				n := c.kcp.Recv(b)
+				c.mux.Unlock()
+				return n, nil
+			}
+
+			buf := make([]byte, size)
+			c.kcp.Recv(buf)
+			n := copy(b, buf)
+			c.pending = buf[n:]
+			c.mux.Unlock()
+			return n, nil
+		}
+		c.mux.Unlock()
+
+		if err := c.wait(c.readC, c.readDeadline.Load()); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Write implements net.Conn
+func (c *Conn) Write(b []byte) (int, error) {
+	for {
+		select {
+		case <-c.done:
+			return 0, c.closedError()
+		default:
+		}
+
+		c.mux.Lock()
+		if c.kcp.WaitSnd() < int(c.kcp.sndWnd)*2 {
+			n := len(b)
+
+			// 单次Send的分片数量不能超过接收窗口
+			limit := int(c.kcp.mss) * (wndRcv - 1)
+			for len(b) > 0 {
+				size := min(len(b), limit)
+				c.kcp.Send(b[:size])
+				b = b[size:]
+			}
+
+			c.kcp.current = currentMs()
+			c.kcp.Flush()
+			c.mux.Unlock()
+			return n, nil
+		}
+		c.mux.Unlock()
+
+		if err := c.wait(c.writeC, c.writeDeadline.Load()); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (c *Conn) wait(ch chan struct{}, deadline time.Time) error {
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-timeout:
+		return os.ErrDeadlineExceeded
+	case <-c.done:
+		return c.closedError()
+	}
+}
+
+func (c *Conn) closedError() error {
+	if c.err != nil {
+		return c.err
+	}
+	return net.ErrClosed
+}
+
+// Close implements net.Conn
+func (c *Conn) Close() error {
+	c.closeWithError(nil)
+	return nil
+}
+
+func (c *Conn) closeWithError(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+
+		if c.listener != nil {
+			c.listener.remove(c)
+		}
+
+		if c.ownConn {
+			_ = c.conn.Close()
+		}
+	})
+}
+
+// LocalAddr implements net.Conn
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr implements net.Conn
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// SetDeadline implements net.Conn
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.readDeadline.Store(t)
+	c.writeDeadline.Store(t)
+	notify(c.readC)
+	notify(c.writeC)
+	return nil
+}
+
+// SetReadDeadline implements net.Conn
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.Store(t)
+	notify(c.readC)
+	return nil
+}
+
+// SetWriteDeadline implements net.Conn
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.writeDeadline.Store(t)
+	notify(c.writeC)
+	return nil
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Listener KCP服务器端监听器
+type Listener struct {
+	conn    net.PacketConn
+	ownConn bool
+	config  *Config
+
+	mux      sync.Mutex
+	sessions map[string]*Conn // remoteAddr => *Conn
+
+	acceptC   chan *Conn
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// Listen 监听指定的UDP地址
+func Listen(laddr string, config *Config) (*Listener, error) {
+	conn, err := net.ListenPacket("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	l := ServeConn(conn, config)
+	l.ownConn = true
+	return l, nil
+}
+
+// ServeConn 在已有的PacketConn上提供KCP服务
+func ServeConn(conn net.PacketConn, config *Config) *Listener {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	l := &Listener{
+		conn:     conn,
+		config:   config,
+		sessions: map[string]*Conn{},
+		acceptC:  make(chan *Conn, 128),
+		done:     make(chan struct{}),
+	}
+
+	go l.readLoop()
+	return l
+}
+
+func (l *Listener) readLoop() {
+	defer l.Close()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, addr, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		} else if n < overhead {
+			continue
+		}
+
+		conv := binary.LittleEndian.Uint32(buf)
+		key := addr.String()
+
+		l.mux.Lock()
+		c, ok := l.sessions[key]
+		if ok && c.kcp.conv != conv {
+			// 客户端使用同一个地址重新建立了连接
+			delete(l.sessions, key)
+			go c.Close()
+			ok = false
+		}
+
+		if !ok {
+			// 只有客户端发送的第一个数据包才能建立新连接，并且不能超过连接数上限
+			if !isHandshake(buf[:n]) || l.isClosed() ||
+				(l.config.MaxSessions > 0 && len(l.sessions) >= l.config.MaxSessions) {
+				l.mux.Unlock()
+				continue
+			}
+
+			c = newConn(conv, l.conn, addr, l.config)
+			c.listener = l
+
+			select {
+			case l.acceptC <- c:
+				l.sessions[key] = c
+			default:
+				// 来不及accept，丢弃
+				l.mux.Unlock()
+				c.listener = nil
+				_ = c.Close()
+				continue
+			}
+		}
+		l.mux.Unlock()
+
+		c.input(buf[:n])
+	}
+}
+
+// isHandshake 新连接的第一个数据包，序号为0并且还没有确认过任何数据
+func isHandshake(data []byte) bool {
+	return data[4] == cmdPush &&
+		binary.LittleEndian.Uint32(data[12:]) == 0 && // sn
+		binary.LittleEndian.Uint32(data[16:]) == 0 // una
+}
+
+func (l *Listener) isClosed() bool {
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *Listener) remove(c *Conn) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	key := c.remote.String()
+	if v, ok := l.sessions[key]; ok && v == c {
+		delete(l.sessions, key)
+	}
+}
+
+// Accept 等待新的连接
+func (l *Listener) Accept() (*Conn, error) {
+	select {
+	case c := <-l.acceptC:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close 停止监听，同时关闭所有已建立的连接
+//
+// 服务器端连接共享监听器的UDP socket，监听器关闭后这些连接也无法继续读写
+func (l *Listener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		l.mux.Lock()
+		close(l.done)
+		sessions := make([]*Conn, 0, len(l.sessions))
+		for _, c := range l.sessions {
+			sessions = append(sessions, c)
+		}
+		l.mux.Unlock()
+
+		for _, c := range sessions {
+			_ = c.Close()
+		}
+
+		if l.ownConn {
+			err = l.conn.Close()
+		}
+	})
+	return err
+}
+
+// Addr 监听地址
+func (l *Listener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
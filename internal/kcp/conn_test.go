@@ -0,0 +1,277 @@
+package kcp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConn(t *testing.T) {
+	l, err := Listen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatalf("listen, %v", err)
+	}
+	defer l.Close()
+
+	// echo server
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	conn, err := Dial(l.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("dial, %v", err)
+	}
+	defer conn.Close()
+
+	for _, size := range []int{1, 1000, 64 * 1024, 512 * 1024} {
+		data := make([]byte, size)
+		rand.Read(data)
+
+		go func() {
+			_, _ = conn.Write(data)
+		}()
+
+		_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		got := make([]byte, size)
+		if _, err := io.ReadFull(conn, got); err != nil {
+			t.Fatalf("read %d bytes, %v", size, err)
+		} else if !bytes.Equal(data, got) {
+			t.Fatalf("echo %d bytes, data mismatch", size)
+		}
+	}
+}
+
+func TestConnDeadline(t *testing.T) {
+	l, err := Listen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatalf("listen, %v", err)
+	}
+	defer l.Close()
+
+	conn, err := Dial(l.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("dial, %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if _, err := conn.Read(make([]byte, 10)); err == nil {
+		t.Fatal("read should timeout")
+	}
+}
+
+func TestConnLoss(t *testing.T) {
+	// 服务器端每5个数据包丢弃1个，包括ACK
+	pc := newFaultyConn(t)
+	pc.drop = func(n int) bool { return n%5 == 0 }
+
+	testEcho(t, pc, 256*1024)
+}
+
+func TestConnReorder(t *testing.T) {
+	// 服务器端交换相邻两个数据包的发送顺序
+	pc := newFaultyConn(t)
+	pc.reorder = true
+
+	testEcho(t, pc, 256*1024)
+}
+
+func TestConnRetransmission(t *testing.T) {
+	// 开始阶段服务器端发出的数据包全部丢失，只能依靠超时重传
+	pc := newFaultyConn(t)
+	blackout := time.Now().Add(300 * time.Millisecond)
+	pc.drop = func(int) bool { return time.Now().Before(blackout) }
+
+	server := testEcho(t, pc, 1000)
+
+	server.mux.Lock()
+	xmit := server.kcp.xmit
+	server.mux.Unlock()
+	if xmit == 0 {
+		t.Fatal("no retransmission")
+	}
+}
+
+func TestListenerHandshake(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxSessions = 1
+
+	l, err := Listen("127.0.0.1:0", config)
+	if err != nil {
+		t.Fatalf("listen, %v", err)
+	}
+	defer l.Close()
+
+	// 不是第一个数据包，不会建立连接
+	udp, err := net.Dial("udp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial udp, %v", err)
+	}
+	defer udp.Close()
+
+	segment := make([]byte, overhead+1)
+	segment[4] = cmdPush
+	segment[12] = 1 // sn
+	segment[20] = 1 // len
+	_, _ = udp.Write(segment)
+
+	first, err := Dial(l.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("dial, %v", err)
+	}
+	defer first.Close()
+	_, _ = first.Write([]byte("a"))
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("accept, %v", err)
+	} else if conn.RemoteAddr().(*net.UDPAddr).Port != first.LocalAddr().(*net.UDPAddr).Port {
+		t.Fatalf("unexpected connection from %s", conn.RemoteAddr())
+	}
+
+	// 超过连接数上限
+	second, err := Dial(l.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("dial, %v", err)
+	}
+	defer second.Close()
+	_, _ = second.Write([]byte("b"))
+
+	select {
+	case conn := <-l.acceptC:
+		t.Fatalf("unexpected connection from %s", conn.RemoteAddr())
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestListenerClose(t *testing.T) {
+	l, err := Listen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatalf("listen, %v", err)
+	}
+
+	client, err := Dial(l.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("dial, %v", err)
+	}
+	defer client.Close()
+	_, _ = client.Write([]byte("a"))
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("accept, %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := io.ReadFull(conn, make([]byte, 1)); err != nil {
+		t.Fatalf("read, %v", err)
+	}
+
+	_ = l.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 10)); !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("server connection not closed, %v", err)
+	}
+}
+
+// testEcho 在pc上启动echo服务器，返回服务器端连接
+func testEcho(t *testing.T, pc net.PacketConn, size int) *Conn {
+	l := ServeConn(pc, nil)
+	defer l.Close()
+
+	accepted := make(chan *Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- conn
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	conn, err := Dial(pc.LocalAddr().String(), nil)
+	if err != nil {
+		t.Fatalf("dial, %v", err)
+	}
+	defer conn.Close()
+
+	data := make([]byte, size)
+	rand.Read(data)
+	go func() {
+		_, _ = conn.Write(data)
+	}()
+
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	got := make([]byte, size)
+	if _, err := io.ReadFull(conn, got); err != nil {
+		t.Fatalf("read %d bytes, %v", size, err)
+	} else if !bytes.Equal(data, got) {
+		t.Fatalf("echo %d bytes, data mismatch", size)
+	}
+
+	return <-accepted
+}
+
+// faultyConn 模拟丢包及乱序的服务器端UDP socket
+type faultyConn struct {
+	net.PacketConn
+
+	drop    func(n int) bool
+	reorder bool
+
+	mux     sync.Mutex
+	count   int
+	delayed []byte
+	addr    net.Addr
+}
+
+func newFaultyConn(t *testing.T) *faultyConn {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp, %v", err)
+	}
+	t.Cleanup(func() { _ = pc.Close() })
+
+	return &faultyConn{PacketConn: pc}
+}
+
+func (fc *faultyConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	fc.mux.Lock()
+	defer fc.mux.Unlock()
+
+	fc.count++
+	if fc.drop != nil && fc.drop(fc.count) {
+		return len(p), nil
+	}
+
+	if fc.reorder {
+		if fc.delayed == nil {
+			fc.delayed, fc.addr = append([]byte(nil), p...), addr
+			return len(p), nil
+		}
+
+		if _, err := fc.PacketConn.WriteTo(p, addr); err != nil {
+			return 0, err
+		}
+		delayed, to := fc.delayed, fc.addr
+		fc.delayed = nil
+		return fc.PacketConn.WriteTo(delayed, to)
+	}
+
+	return fc.PacketConn.WriteTo(p, addr)
+}
@@ -0,0 +1,724 @@
+// Package kcp KCP协议实现，移植自 https://github.com/skywind3000/kcp (ikcp.c)
+//
+// 只包含KCP协议本身，数据包格式与ikcp.c一致，可以直接与使用ikcp.c的客户端通讯；
+// 没有使用kcp-go是因为网关不需要它的FEC及加密层，以及由此引入的依赖，
+// 同时网关需要在共享的PacketConn上提供服务，并在关闭监听器时关闭所有连接
+package kcp
+
+import (
+	"encoding/binary"
+)
+
+const (
+	rtoNoDelay   = 30 // nodelay模式下的最小RTO
+	rtoMin       = 100
+	rtoDef       = 200
+	rtoMax       = 60000
+	cmdPush      = 81 // 数据
+	cmdAck       = 82 // ack
+	cmdWask      = 83 // 询问窗口大小
+	cmdWins      = 84 // 告知窗口大小
+	askSend      = 1
+	askTell      = 2
+	wndSnd       = 32
+	wndRcv       = 128
+	mtuDef       = 1400
+	intervalDef  = 100
+	overhead     = 24
+	deadLink     = 20
+	threshInit   = 2
+	threshMin    = 2
+	probeInit    = 7000   // 7秒后探测窗口大小
+	probeLimit   = 120000 // 最长120秒探测一次
+	fastAckLimit = 5
+)
+
+type segment struct {
+	conv     uint32
+	cmd      uint8
+	frg      uint8
+	wnd      uint16
+	ts       uint32
+	sn       uint32
+	una      uint32
+	resendts uint32
+	rto      uint32
+	fastack  uint32
+	xmit     uint32
+	data     []byte
+}
+
+func (seg *segment) encode(ptr []byte) {
+	binary.LittleEndian.PutUint32(ptr, seg.conv)
+	ptr[4] = seg.cmd
+	ptr[5] = seg.frg
+	binary.LittleEndian.PutUint16(ptr[6:], seg.wnd)
+	binary.LittleEndian.PutUint32(ptr[8:], seg.ts)
+	binary.LittleEndian.PutUint32(ptr[12:], seg.sn)
+	binary.LittleEndian.PutUint32(ptr[16:], seg.una)
+	binary.LittleEndian.PutUint32(ptr[20:], uint32(len(seg.data)))
+}
+
+type ackItem struct {
+	sn uint32
+	ts uint32
+}
+
+// kcp ARQ协议状态机，非并发安全
+type kcp struct {
+	conv, mtu, mss     uint32
+	dead               bool
+	sndUna, sndNxt     uint32
+	rcvNxt             uint32
+	ssthresh           uint32
+	rxRttval, rxSrtt   int32
+	rxRto, rxMinrto    uint32
+	sndWnd, rcvWnd     uint32
+	rmtWnd, cwnd       uint32
+	probe              uint32
+	current, interval  uint32
+	tsFlush, xmit      uint32
+	nodelay, updated   uint32
+	tsProbe, probeWait uint32
+	deadLink, incr     uint32
+	fastresend         int32
+	fastlimit          int32
+	nocwnd, stream     int32
+
+	sndQueue []segment
+	rcvQueue []segment
+	sndBuf   []segment
+	rcvBuf   []segment
+	acklist  []ackItem
+
+	buffer []byte
+	output func(data []byte)
+}
+
+func newKCP(conv uint32, output func(data []byte)) *kcp {
+	return &kcp{
+		conv:      conv,
+		sndWnd:    wndSnd,
+		rcvWnd:    wndRcv,
+		rmtWnd:    wndRcv,
+		mtu:       mtuDef,
+		mss:       mtuDef - overhead,
+		buffer:    make([]byte, (mtuDef+overhead)*3),
+		rxRto:     rtoDef,
+		rxMinrto:  rtoMin,
+		interval:  intervalDef,
+		tsFlush:   intervalDef,
+		ssthresh:  threshInit,
+		fastlimit: fastAckLimit,
+		deadLink:  deadLink,
+		output:    output,
+	}
+}
+
+// Recv 读取一个完整的数据包，返回读取长度，没有可读数据时返回负数
+func (k *kcp) Recv(buffer []byte) int {
+	if len(k.rcvQueue) == 0 {
+		return -1
+	}
+
+	peekSize := k.PeekSize()
+	if peekSize < 0 {
+		return -2
+	} else if peekSize > len(buffer) {
+		return -3
+	}
+
+	recover := uint32(len(k.rcvQueue)) >= k.rcvWnd
+
+	n, count := 0, 0
+	for _, seg := range k.rcvQueue {
+		copy(buffer[n:], seg.data)
+		n += len(seg.data)
+		count++
+		if seg.frg == 0 {
+			break
+		}
+	}
+	k.rcvQueue = removeFront(k.rcvQueue, count)
+
+	k.moveToRcvQueue()
+
+	// 接收窗口从满变为非满，主动告知对端窗口大小
+	if uint32(len(k.rcvQueue)) < k.rcvWnd && recover {
+		k.probe |= askTell
+	}
+	return n
+}
+
+// PeekSize 下一个完整数据包的长度
+func (k *kcp) PeekSize() int {
+	if len(k.rcvQueue) == 0 {
+		return -1
+	}
+
+	seg := k.rcvQueue[0]
+	if seg.frg == 0 {
+		return len(seg.data)
+	} else if len(k.rcvQueue) < int(seg.frg)+1 {
+		return -1
+	}
+
+	length := 0
+	for _, seg := range k.rcvQueue {
+		length += len(seg.data)
+		if seg.frg == 0 {
+			break
+		}
+	}
+	return length
+}
+
+// Send 把数据放入发送队列
+func (k *kcp) Send(buffer []byte) int {
+	if len(buffer) == 0 {
+		return -1
+	}
+
+	// 流模式下尽量填满最后一个分片
+	if k.stream != 0 {
+		if n := len(k.sndQueue); n > 0 {
+			seg := &k.sndQueue[n-1]
+			if l := uint32(len(seg.data)); l < k.mss {
+				extend := min(len(buffer), int(k.mss-l))
+				seg.data = append(seg.data, buffer[:extend]...)
+				seg.frg = 0
+				buffer = buffer[extend:]
+			}
+		}
+
+		if len(buffer) == 0 {
+			return 0
+		}
+	}
+
+	count := 1
+	if len(buffer) > int(k.mss) {
+		count = (len(buffer) + int(k.mss) - 1) / int(k.mss)
+	}
+	if count >= wndRcv {
+		return -2
+	}
+
+	for i := 0; i < count; i++ {
+		size := min(len(buffer), int(k.mss))
+		seg := segment{
+			data: append([]byte(nil), buffer[:size]...),
+		}
+		if k.stream == 0 {
+			seg.frg = uint8(count - i - 1)
+		}
+
+		k.sndQueue = append(k.sndQueue, seg)
+		buffer = buffer[size:]
+	}
+	return 0
+}
+
+func (k *kcp) updateAck(rtt int32) {
+	if k.rxSrtt == 0 {
+		k.rxSrtt = rtt
+		k.rxRttval = rtt / 2
+	} else {
+		delta := rtt - k.rxSrtt
+		if delta < 0 {
+			delta = -delta
+		}
+		k.rxRttval = (3*k.rxRttval + delta) / 4
+		k.rxSrtt = (7*k.rxSrtt + rtt) / 8
+		if k.rxSrtt < 1 {
+			k.rxSrtt = 1
+		}
+	}
+
+	rto := uint32(k.rxSrtt) + max(k.interval, uint32(4*k.rxRttval))
+	k.rxRto = min(max(k.rxMinrto, rto), rtoMax)
+}
+
+func (k *kcp) shrinkBuf() {
+	if len(k.sndBuf) > 0 {
+		k.sndUna = k.sndBuf[0].sn
+	} else {
+		k.sndUna = k.sndNxt
+	}
+}
+
+func (k *kcp) parseAck(sn uint32) {
+	if timediff(sn, k.sndUna) < 0 || timediff(sn, k.sndNxt) >= 0 {
+		return
+	}
+
+	for i, seg := range k.sndBuf {
+		if sn == seg.sn {
+			k.sndBuf = append(k.sndBuf[:i], k.sndBuf[i+1:]...)
+			break
+		} else if timediff(sn, seg.sn) < 0 {
+			break
+		}
+	}
+}
+
+func (k *kcp) parseUna(una uint32) {
+	count := 0
+	for _, seg := range k.sndBuf {
+		if timediff(una, seg.sn) > 0 {
+			count++
+		} else {
+			break
+		}
+	}
+	k.sndBuf = removeFront(k.sndBuf, count)
+}
+
+func (k *kcp) parseFastack(sn uint32) {
+	if timediff(sn, k.sndUna) < 0 || timediff(sn, k.sndNxt) >= 0 {
+		return
+	}
+
+	for i := range k.sndBuf {
+		seg := &k.sndBuf[i]
+		if timediff(sn, seg.sn) < 0 {
+			break
+		} else if sn != seg.sn {
+			seg.fastack++
+		}
+	}
+}
+
+func (k *kcp) parseData(newseg segment) {
+	sn := newseg.sn
+	if timediff(sn, k.rcvNxt+k.rcvWnd) >= 0 || timediff(sn, k.rcvNxt) < 0 {
+		return
+	}
+
+	insertIdx := 0
+	repeat := false
+	for i := len(k.rcvBuf) - 1; i >= 0; i-- {
+		seg := k.rcvBuf[i]
+		if seg.sn == sn {
+			repeat = true
+			break
+		} else if timediff(sn, seg.sn) > 0 {
+			insertIdx = i + 1
+			break
+		}
+	}
+
+	if !repeat {
+		newseg.data = append([]byte(nil), newseg.data...)
+
+		k.rcvBuf = append(k.rcvBuf, segment{})
+		copy(k.rcvBuf[insertIdx+1:], k.rcvBuf[insertIdx:])
+		k.rcvBuf[insertIdx] = newseg
+	}
+
+	k.moveToRcvQueue()
+}
+
+// 把rcvBuf内连续的数据移动到rcvQueue
+func (k *kcp) moveToRcvQueue() {
+	count := 0
+	for _, seg := range k.rcvBuf {
+		if seg.sn == k.rcvNxt && uint32(len(k.rcvQueue)+count) < k.rcvWnd {
+			k.rcvNxt++
+			count++
+		} else {
+			break
+		}
+	}
+
+	if count > 0 {
+		k.rcvQueue = append(k.rcvQueue, k.rcvBuf[:count]...)
+		k.rcvBuf = removeFront(k.rcvBuf, count)
+	}
+}
+
+// Input 处理收到的底层数据包
+func (k *kcp) Input(data []byte) int {
+	if len(data) < overhead {
+		return -1
+	}
+
+	prevUna := k.sndUna
+
+	var (
+		flag   bool
+		maxack uint32
+	)
+
+	for len(data) >= overhead {
+		conv := binary.LittleEndian.Uint32(data)
+		if conv != k.conv {
+			return -1
+		}
+
+		cmd := data[4]
+		frg := data[5]
+		wnd := binary.LittleEndian.Uint16(data[6:])
+		ts := binary.LittleEndian.Uint32(data[8:])
+		sn := binary.LittleEndian.Uint32(data[12:])
+		una := binary.LittleEndian.Uint32(data[16:])
+		length := binary.LittleEndian.Uint32(data[20:])
+		data = data[overhead:]
+
+		if uint32(len(data)) < length {
+			return -2
+		}
+
+		switch cmd {
+		case cmdPush, cmdAck, cmdWask, cmdWins:
+		default:
+			return -3
+		}
+
+		k.rmtWnd = uint32(wnd)
+		k.parseUna(una)
+		k.shrinkBuf()
+
+		switch cmd {
+		case cmdAck:
+			if rtt := timediff(k.current, ts); rtt >= 0 {
+				k.updateAck(rtt)
+			}
+			k.parseAck(sn)
+			k.shrinkBuf()
+
+			if !flag {
+				flag = true
+				maxack = sn
+			} else if timediff(sn, maxack) > 0 {
+				maxack = sn
+			}
+		case cmdPush:
+			if timediff(sn, k.rcvNxt+k.rcvWnd) < 0 {
+				k.acklist = append(k.acklist, ackItem{sn: sn, ts: ts})
+
+				if timediff(sn, k.rcvNxt) >= 0 {
+					k.parseData(segment{
+						conv: conv,
+						cmd:  cmd,
+						frg:  frg,
+						wnd:  wnd,
+						ts:   ts,
+						sn:   sn,
+						una:  una,
+						data: data[:length],
+					})
+				}
+			}
+		case cmdWask:
+			k.probe |= askTell
+		case cmdWins:
+			// do nothing
+		}
+
+		data = data[length:]
+	}
+
+	if flag {
+		k.parseFastack(maxack)
+	}
+
+	// 拥塞窗口增长
+	if timediff(k.sndUna, prevUna) > 0 && k.cwnd < k.rmtWnd {
+		mss := k.mss
+		if k.cwnd < k.ssthresh {
+			k.cwnd++
+			k.incr += mss
+		} else {
+			if k.incr < mss {
+				k.incr = mss
+			}
+			k.incr += (mss*mss)/k.incr + (mss / 16)
+			if (k.cwnd+1)*mss <= k.incr {
+				k.cwnd = (k.incr + mss - 1) / mss
+			}
+		}
+
+		if k.cwnd > k.rmtWnd {
+			k.cwnd = k.rmtWnd
+			k.incr = k.rmtWnd * mss
+		}
+	}
+
+	return 0
+}
+
+func (k *kcp) wndUnused() uint16 {
+	if n := uint32(len(k.rcvQueue)); n < k.rcvWnd {
+		return uint16(k.rcvWnd - n)
+	}
+	return 0
+}
+
+// Flush 发送ack、窗口探测以及待发送数据
+func (k *kcp) Flush() {
+	if k.updated == 0 {
+		return
+	}
+
+	current := k.current
+	buffer := k.buffer
+	ptr := 0
+
+	makeSpace := func(space int) {
+		if ptr+space > int(k.mtu) {
+			k.output(buffer[:ptr])
+			ptr = 0
+		}
+	}
+
+	seg := segment{
+		conv: k.conv,
+		cmd:  cmdAck,
+		wnd:  k.wndUnused(),
+		una:  k.rcvNxt,
+	}
+
+	// ack
+	for _, ack := range k.acklist {
+		makeSpace(overhead)
+		seg.sn, seg.ts = ack.sn, ack.ts
+		seg.encode(buffer[ptr:])
+		ptr += overhead
+	}
+	k.acklist = k.acklist[:0]
+
+	// 对端窗口为0时，定时探测窗口大小
+	if k.rmtWnd == 0 {
+		if k.probeWait == 0 {
+			k.probeWait = probeInit
+			k.tsProbe = current + k.probeWait
+		} else if timediff(current, k.tsProbe) >= 0 {
+			if k.probeWait < probeInit {
+				k.probeWait = probeInit
+			}
+			k.probeWait += k.probeWait / 2
+			if k.probeWait > probeLimit {
+				k.probeWait = probeLimit
+			}
+			k.tsProbe = current + k.probeWait
+			k.probe |= askSend
+		}
+	} else {
+		k.tsProbe = 0
+		k.probeWait = 0
+	}
+
+	seg.sn, seg.ts = 0, 0
+	if k.probe&askSend != 0 {
+		seg.cmd = cmdWask
+		makeSpace(overhead)
+		seg.encode(buffer[ptr:])
+		ptr += overhead
+	}
+
+	if k.probe&askTell != 0 {
+		seg.cmd = cmdWins
+		makeSpace(overhead)
+		seg.encode(buffer[ptr:])
+		ptr += overhead
+	}
+	k.probe = 0
+
+	cwnd := min(k.sndWnd, k.rmtWnd)
+	if k.nocwnd == 0 {
+		cwnd = min(k.cwnd, cwnd)
+	}
+
+	// 把发送队列内的数据移入发送缓冲区
+	count := 0
+	for timediff(k.sndNxt, k.sndUna+cwnd) < 0 && count < len(k.sndQueue) {
+		newseg := k.sndQueue[count]
+		newseg.conv = k.conv
+		newseg.cmd = cmdPush
+		newseg.wnd = seg.wnd
+		newseg.ts = current
+		newseg.sn = k.sndNxt
+		newseg.una = k.rcvNxt
+		newseg.resendts = current
+		newseg.rto = k.rxRto
+		newseg.fastack = 0
+		newseg.xmit = 0
+		k.sndBuf = append(k.sndBuf, newseg)
+
+		k.sndNxt++
+		count++
+	}
+	k.sndQueue = removeFront(k.sndQueue, count)
+
+	resent := uint32(k.fastresend)
+	if k.fastresend <= 0 {
+		resent = 0xffffffff
+	}
+
+	rtomin := k.rxRto >> 3
+	if k.nodelay != 0 {
+		rtomin = 0
+	}
+
+	var change, lost bool
+	for i := range k.sndBuf {
+		segment := &k.sndBuf[i]
+
+		needsend := false
+		if segment.xmit == 0 {
+			needsend = true
+			segment.xmit++
+			segment.rto = k.rxRto
+			segment.resendts = current + segment.rto + rtomin
+		} else if timediff(current, segment.resendts) >= 0 {
+			needsend = true
+			segment.xmit++
+			k.xmit++
+			if k.nodelay == 0 {
+				segment.rto += max(segment.rto, k.rxRto)
+			} else {
+				segment.rto += k.rxRto / 2
+			}
+			segment.resendts = current + segment.rto
+			lost = true
+		} else if segment.fastack >= resent {
+			if segment.xmit <= uint32(k.fastlimit) || k.fastlimit <= 0 {
+				needsend = true
+				segment.xmit++
+				segment.fastack = 0
+				segment.resendts = current + segment.rto
+				change = true
+			}
+		}
+
+		if needsend {
+			segment.ts = current
+			segment.wnd = seg.wnd
+			segment.una = k.rcvNxt
+
+			makeSpace(overhead + len(segment.data))
+			segment.encode(buffer[ptr:])
+			ptr += overhead
+			ptr += copy(buffer[ptr:], segment.data)
+
+			if segment.xmit >= k.deadLink {
+				k.dead = true
+			}
+		}
+	}
+
+	if ptr > 0 {
+		k.output(buffer[:ptr])
+	}
+
+	// 快速重传，调整慢启动阈值
+	if change {
+		inflight := k.sndNxt - k.sndUna
+		k.ssthresh = max(inflight/2, threshMin)
+		k.cwnd = k.ssthresh + resent
+		k.incr = k.cwnd * k.mss
+	}
+
+	// 超时重传，重新进入慢启动
+	if lost {
+		k.ssthresh = max(cwnd/2, threshMin)
+		k.cwnd = 1
+		k.incr = k.mss
+	}
+
+	if k.cwnd < 1 {
+		k.cwnd = 1
+		k.incr = k.mss
+	}
+}
+
+// Update 按照interval间隔定时调用，current为毫秒时间戳
+func (k *kcp) Update(current uint32) {
+	k.current = current
+
+	if k.updated == 0 {
+		k.updated = 1
+		k.tsFlush = current
+	}
+
+	slap := timediff(current, k.tsFlush)
+	if slap >= 10000 || slap < -10000 {
+		k.tsFlush = current
+		slap = 0
+	}
+
+	if slap >= 0 {
+		k.tsFlush += k.interval
+		if timediff(current, k.tsFlush) >= 0 {
+			k.tsFlush = current + k.interval
+		}
+		k.Flush()
+	}
+}
+
+// SetMTU 设置最大传输单元
+func (k *kcp) SetMTU(mtu int) bool {
+	if mtu < 50 || mtu < overhead {
+		return false
+	}
+
+	k.buffer = make([]byte, (mtu+overhead)*3)
+	k.mtu = uint32(mtu)
+	k.mss = k.mtu - overhead
+	return true
+}
+
+// NoDelay 设置传输模式，参数小于0时不修改
+func (k *kcp) NoDelay(nodelay, interval, resend, nc int) {
+	if nodelay >= 0 {
+		k.nodelay = uint32(nodelay)
+		if nodelay != 0 {
+			k.rxMinrto = rtoNoDelay
+		} else {
+			k.rxMinrto = rtoMin
+		}
+	}
+
+	if interval >= 0 {
+		k.interval = uint32(min(max(interval, 10), 5000))
+	}
+
+	if resend >= 0 {
+		k.fastresend = int32(resend)
+	}
+
+	if nc >= 0 {
+		k.nocwnd = int32(nc)
+	}
+}
+
+// WndSize 设置收发窗口大小
+func (k *kcp) WndSize(sndwnd, rcvwnd int) {
+	if sndwnd > 0 {
+		k.sndWnd = uint32(sndwnd)
+	}
+
+	if rcvwnd > 0 {
+		k.rcvWnd = uint32(max(rcvwnd, wndRcv))
+	}
+}
+
+// WaitSnd 等待发送的数据包数量
+func (k *kcp) WaitSnd() int {
+	return len(k.sndBuf) + len(k.sndQueue)
+}
+
+func timediff(later, earlier uint32) int32 {
+	return int32(later - earlier)
+}
+
+func removeFront(q []segment, n int) []segment {
+	if n == 0 {
+		return q
+	}
+
+	m := copy(q, q[n:])
+	clear(q[m:])
+	return q[:m]
+}
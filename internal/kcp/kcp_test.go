@@ -0,0 +1,227 @@
+package kcp
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSegmentEncode(t *testing.T) {
+	// 与ikcp.c的ikcp_encode_seg一致，24字节头部，所有字段都是小端序
+	seg := segment{
+		conv: 0x01020304,
+		cmd:  cmdPush,
+		frg:  2,
+		wnd:  0x0506,
+		ts:   0x0708090a,
+		sn:   0x0b0c0d0e,
+		una:  0x0f101112,
+		data: []byte("abc"),
+	}
+
+	buf := make([]byte, overhead)
+	seg.encode(buf)
+
+	expected := []byte{
+		0x04, 0x03, 0x02, 0x01, // conv
+		cmdPush,    // cmd
+		0x02,       // frg
+		0x06, 0x05, // wnd
+		0x0a, 0x09, 0x08, 0x07, // ts
+		0x0e, 0x0d, 0x0c, 0x0b, // sn
+		0x12, 0x11, 0x10, 0x0f, // una
+		0x03, 0x00, 0x00, 0x00, // len
+	}
+	if !bytes.Equal(buf, expected) {
+		t.Fatalf("unexpected segment header\nexpected %x\ngot      %x", expected, buf)
+	}
+}
+
+func TestKCPWindow(t *testing.T) {
+	t.Run("send window", func(t *testing.T) {
+		k := newKCP(1, func([]byte) {})
+		k.NoDelay(1, 10, 2, 1)
+		k.WndSize(4, 0)
+
+		for i := 0; i < 10; i++ {
+			k.Send([]byte{byte(i)})
+		}
+		k.Update(0)
+
+		if len(k.sndBuf) != 4 || len(k.sndQueue) != 6 {
+			t.Fatalf("expected 4 segments in flight and 6 queued, got %d and %d", len(k.sndBuf), len(k.sndQueue))
+		}
+	})
+
+	t.Run("receive window", func(t *testing.T) {
+		p := newTestPair()
+		p.a.WndSize(wndRcv*2, 0)
+
+		const total = wndRcv * 2
+		for i := 0; i < total; i++ {
+			p.a.Send([]byte(fmt.Sprint(i)))
+		}
+
+		// 接收方不读取数据，发送方最多发送接收窗口大小的数据
+		p.run(1000, func() bool { return p.a.rmtWnd == 0 })
+		if p.a.rmtWnd != 0 {
+			t.Fatalf("remote window not closed, %d", p.a.rmtWnd)
+		} else if n := len(p.b.rcvQueue); n != wndRcv {
+			t.Fatalf("expected %d segments in receive queue, got %d", wndRcv, n)
+		}
+
+		// 接收方读取之后窗口重新打开，剩余的数据可以继续发送
+		buf := make([]byte, mtuDef)
+		var received []string
+		p.run(10000, func() bool {
+			for {
+				n := p.b.Recv(buf)
+				if n < 0 {
+					break
+				}
+				received = append(received, string(buf[:n]))
+			}
+			return len(received) == total
+		})
+
+		if len(received) != total {
+			t.Fatalf("expected %d packets, got %d", total, len(received))
+		}
+		for i, v := range received {
+			if v != fmt.Sprint(i) {
+				t.Fatalf("packet %d out of order, %s", i, v)
+			}
+		}
+	})
+}
+
+func TestKCPLoss(t *testing.T) {
+	p := newTestPair()
+	// 每3个数据包丢弃1个，包括重传的数据包
+	p.drop = func(n int) bool { return n%3 == 0 }
+
+	const total = 100
+	for i := 0; i < total; i++ {
+		p.a.Send([]byte(fmt.Sprint(i)))
+	}
+
+	received := p.receive(total)
+	if len(received) != total {
+		t.Fatalf("expected %d packets, got %d", total, len(received))
+	}
+	for i, v := range received {
+		if v != fmt.Sprint(i) {
+			t.Fatalf("packet %d out of order, %s", i, v)
+		}
+	}
+}
+
+func TestKCPReorder(t *testing.T) {
+	p := newTestPair()
+	p.reorder = true
+
+	// 超过mss的数据会被分片，分片乱序到达之后需要重新组装
+	large := bytes.Repeat([]byte("0123456789"), 500)
+	p.a.Send(large)
+
+	const total = 50
+	for i := 0; i < total; i++ {
+		p.a.Send([]byte(fmt.Sprint(i)))
+	}
+
+	received := p.receive(total + 1)
+	if len(received) != total+1 {
+		t.Fatalf("expected %d packets, got %d", total+1, len(received))
+	} else if received[0] != string(large) {
+		t.Fatal("fragmented packet not reassembled")
+	}
+	for i, v := range received[1:] {
+		if v != fmt.Sprint(i) {
+			t.Fatalf("packet %d out of order, %s", i, v)
+		}
+	}
+}
+
+func TestKCPDeadLink(t *testing.T) {
+	p := newTestPair()
+	p.drop = func(int) bool { return true }
+
+	p.a.Send([]byte("ping"))
+	p.run(100000, func() bool { return p.a.dead })
+
+	if !p.a.dead {
+		t.Fatal("link not dead")
+	} else if xmit := p.a.sndBuf[0].xmit; xmit != deadLink {
+		t.Fatalf("expected %d transmissions, got %d", deadLink, xmit)
+	}
+}
+
+// testPair 在模拟时钟下直接连接的两个kcp
+type testPair struct {
+	a, b    *kcp
+	ab, ba  [][]byte
+	sent    int              // a发往b的数据包数量
+	drop    func(n int) bool // 是否丢弃a发往b的第n个数据包
+	reorder bool             // 每次推进时逆序投递a发往b的数据包
+	now     uint32
+}
+
+func newTestPair() *testPair {
+	p := &testPair{}
+	p.a = newKCP(1, func(data []byte) {
+		p.sent++
+		if p.drop == nil || !p.drop(p.sent) {
+			p.ab = append(p.ab, bytes.Clone(data))
+		}
+	})
+	p.b = newKCP(1, func(data []byte) {
+		p.ba = append(p.ba, bytes.Clone(data))
+	})
+
+	for _, k := range []*kcp{p.a, p.b} {
+		k.NoDelay(1, 10, 2, 1)
+	}
+	return p
+}
+
+// run 每次推进10毫秒，直到done返回true或者达到steps次
+func (p *testPair) run(steps int, done func() bool) {
+	for i := 0; i < steps; i++ {
+		p.now += 10
+		p.a.Update(p.now)
+		p.b.Update(p.now)
+
+		if p.reorder {
+			slices.Reverse(p.ab)
+		}
+		for _, data := range p.ab {
+			p.b.Input(data)
+		}
+		for _, data := range p.ba {
+			p.a.Input(data)
+		}
+		p.ab, p.ba = p.ab[:0], p.ba[:0]
+
+		if done() {
+			return
+		}
+	}
+}
+
+// receive 推进时钟并读取b收到的数据，直到收到total个数据包
+func (p *testPair) receive(total int) []string {
+	buf := make([]byte, mtuDef*wndRcv)
+	var received []string
+	p.run(100000, func() bool {
+		for {
+			n := p.b.Recv(buf)
+			if n < 0 {
+				break
+			}
+			received = append(received, string(buf[:n]))
+		}
+		return len(received) >= total
+	})
+	return received
+}
@@ -0,0 +1,506 @@
+// Package metrics 框架内置的prometheus指标
+//
+// 指标注册在每个Metrics实例独立的prometheus.Registry内，通过cluster.Registry.Metrics()获取服务注册表使用的实例，
+// component/metrics.Server以http方式输出
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics 一组独立注册的prometheus指标，每个服务注册表拥有自己的实例
+//
+// 所有的统计方法都允许nil接收者，不做任何记录
+type Metrics struct {
+	registry *prometheus.Registry
+
+	grpcReqs         *prometheus.CounterVec
+	grpcDurs         *prometheus.HistogramVec
+	serverReqs       *prometheus.CounterVec
+	serverDurs       *prometheus.HistogramVec
+	rpcErrors        *prometheus.CounterVec
+	sessionTotal     *prometheus.CounterVec
+	sessionCount     *prometheus.GaugeVec
+	sessionRejected  *prometheus.CounterVec
+	sessionLifetime  *prometheus.HistogramVec
+	payloadSize      prometheus.Histogram
+	payloadSizeTotal *prometheus.CounterVec
+	sendFailures     *prometheus.CounterVec
+	sendDurs         *prometheus.HistogramVec
+	multicastFanout  prometheus.Histogram
+	multicastMisses  prometheus.Counter
+	statefulRoutes   prometheus.Gauge
+	queueTotal       *prometheus.CounterVec
+	queueDurs        *prometheus.HistogramVec
+	serviceConflicts *prometheus.GaugeVec
+	nodeEjections    *prometheus.CounterVec
+	ejectedNodes     prometheus.Gauge
+	watchEvents      *prometheus.CounterVec
+	nodes            *prometheus.GaugeVec
+	goPoolRunning    prometheus.Gauge
+	goPoolCapacity   prometheus.Gauge
+	goPoolWaiting    prometheus.Gauge
+	goPoolRejected   prometheus.Counter
+}
+
+// New 创建metrics，所有指标注册在新的prometheus.Registry内
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		grpcReqs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_requests_total",
+				Help: "Number of grpc requests",
+			},
+			[]string{"method", "code"},
+		),
+
+		grpcDurs: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_request_duration_seconds",
+				Help:    "Duration of grpc requests",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method"},
+		),
+
+		serverReqs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_server_requests_total",
+				Help: "Number of grpc requests handled by service node",
+			},
+			[]string{"method", "code"},
+		),
+
+		serverDurs: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_server_request_duration_seconds",
+				Help:    "Duration of grpc requests handled by service node",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method"},
+		),
+
+		rpcErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rpc_errors_total",
+				Help: "Number of RPCError replied to clients",
+			},
+			[]string{"service", "code"},
+		),
+
+		sessionTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "session_total",
+				Help: "Total number of sessions",
+			},
+			[]string{"type"},
+		),
+
+		sessionCount: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "session_count",
+				Help: "Number of sessions",
+			},
+			[]string{"type"},
+		),
+
+		sessionRejected: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "session_rejected_total",
+				Help: "Total number of rejected sessions",
+			},
+			[]string{"type", "reason"},
+		),
+
+		sessionLifetime: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "session_lifetime_seconds",
+				Help: "Lifetime of sessions",
+				Buckets: []float64{
+					10, 60, 5 * 60, 15 * 60, 30 * 60,
+					60 * 60, 2 * 60 * 60, 4 * 60 * 60, 8 * 60 * 60,
+				},
+			},
+			[]string{"type"},
+		),
+
+		payloadSize: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name: "payload_size",
+				Help: "Size of network payload",
+				Buckets: []float64{
+					1024,        // 1k
+					4 * 1024,    // 4k
+					8 * 1024,    // 8k
+					16 * 1024,   // 16k
+					32 * 1024,   // 32k
+					64 * 1024,   // 64k default max size
+					512 * 1024,  // 512k
+					1024 * 1024, // 1M
+				},
+			},
+		),
+
+		payloadSizeTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payload_size_total",
+				Help: "Total size of network payload",
+			},
+			[]string{"type"},
+		),
+
+		sendFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "session_send_failures_total",
+				Help: "Number of failed writes to sessions",
+			},
+			[]string{"type"},
+		),
+
+		sendDurs: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "session_send_duration_seconds",
+				Help:    "Duration of writes to sessions",
+				Buckets: []float64{.0005, .001, .005, .01, .05, .1, .5, 1, 5},
+			},
+			[]string{"type"},
+		),
+
+		multicastFanout: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "multicast_fanout",
+				Help:    "Number of receivers of multicast messages",
+				Buckets: []float64{1, 2, 5, 10, 50, 100, 500, 1000},
+			},
+		),
+
+		multicastMisses: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "multicast_misses_total",
+				Help: "Number of multicast receivers not connected to this gateway",
+			},
+		),
+
+		statefulRoutes: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "stateful_routes",
+				Help: "Number of sessions in stateful route table",
+			},
+		),
+
+		queueTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "message_queue_total",
+				Help: "Total number of queue message",
+			},
+			[]string{"topic"},
+		),
+
+		queueDurs: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "message_queue_delay",
+				Help:    "Duration of queue message from publish to consume",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"topic"},
+		),
+
+		serviceConflicts: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "service_code_conflicts",
+				Help: "Number of nodes whose service conflicts with the service code",
+			},
+			[]string{"code"},
+		),
+
+		nodeEjections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_node_ejections_total",
+				Help: "Total number of ejected grpc node endpoints",
+			},
+			[]string{"reason"},
+		),
+
+		ejectedNodes: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "grpc_node_ejected",
+				Help: "Number of currently ejected grpc node endpoints",
+			},
+		),
+
+		watchEvents: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "registry_watch_events_total",
+				Help: "Number of registry watch events",
+			},
+			[]string{"type"},
+		),
+
+		nodes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cluster_nodes",
+				Help: "Number of cluster nodes by state",
+			},
+			[]string{"state"},
+		),
+
+		goPoolRunning: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "gopool_running",
+				Help: "Number of running goroutines in pool",
+			},
+		),
+
+		goPoolCapacity: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "gopool_capacity",
+				Help: "Capacity of goroutine pool",
+			},
+		),
+
+		goPoolWaiting: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "gopool_waiting",
+				Help: "Number of tasks waiting for goroutine pool",
+			},
+		),
+
+		goPoolRejected: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "gopool_rejected_total",
+				Help: "Number of tasks rejected by goroutine pool",
+			},
+		),
+	}
+
+	m.registry.MustRegister(
+		m.grpcReqs,
+		m.grpcDurs,
+		m.serverReqs,
+		m.serverDurs,
+		m.rpcErrors,
+		m.sessionTotal,
+		m.sessionCount,
+		m.sessionRejected,
+		m.sessionLifetime,
+		m.payloadSize,
+		m.payloadSizeTotal,
+		m.sendFailures,
+		m.sendDurs,
+		m.multicastFanout,
+		m.multicastMisses,
+		m.statefulRoutes,
+		m.queueTotal,
+		m.queueDurs,
+		m.serviceConflicts,
+		m.nodeEjections,
+		m.ejectedNodes,
+		m.watchEvents,
+		m.nodes,
+		m.goPoolRunning,
+		m.goPoolCapacity,
+		m.goPoolWaiting,
+		m.goPoolRejected,
+	)
+	return m
+}
+
+// Registry 注册了所有指标的prometheus.Registry
+func (m *Metrics) Registry() *prometheus.Registry {
+	if m == nil {
+		return nil
+	}
+	return m.registry
+}
+
+// IncrGRPCRequests 统计网关转发的grpc请求
+func (m *Metrics) IncrGRPCRequests(method string, err error, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.grpcReqs.With(prometheus.Labels{
+		"method": method,
+		"code":   status.Code(err).String(),
+	}).Inc()
+
+	m.grpcDurs.With(prometheus.Labels{
+		"method": method,
+	}).Observe(duration.Seconds())
+}
+
+// IncrServerRequests 统计服务节点处理的grpc请求
+func (m *Metrics) IncrServerRequests(method string, err error, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.serverReqs.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.serverDurs.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// IncrRPCError 统计下行给客户端的RPCError
+func (m *Metrics) IncrRPCError(serviceCode int32, code codes.Code) {
+	if m == nil {
+		return
+	}
+
+	m.rpcErrors.WithLabelValues(strconv.Itoa(int(serviceCode)), code.String()).Inc()
+}
+
+// IncrGatewaySession 增加网关session计数
+func (m *Metrics) IncrGatewaySession(sessionType string) {
+	if m == nil {
+		return
+	}
+
+	m.sessionTotal.WithLabelValues(sessionType).Inc()
+	m.sessionCount.WithLabelValues(sessionType).Inc()
+}
+
+// DecrGatewaySession 减少网关session计数，并记录session存活时间
+func (m *Metrics) DecrGatewaySession(sessionType string, lifetime time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.sessionCount.WithLabelValues(sessionType).Dec()
+	m.sessionLifetime.WithLabelValues(sessionType).Observe(lifetime.Seconds())
+}
+
+// IncrSessionRejected 统计被拒绝的网关session
+func (m *Metrics) IncrSessionRejected(sessionType string, reason string) {
+	if m == nil {
+		return
+	}
+
+	m.sessionRejected.WithLabelValues(sessionType, reason).Inc()
+}
+
+// IncrPayloadSize 统计网络包大小
+func (m *Metrics) IncrPayloadSize(sessionType string, size int) {
+	if m == nil {
+		return
+	}
+
+	m.payloadSize.Observe(float64(size))
+	m.payloadSizeTotal.WithLabelValues(sessionType).Add(float64(size))
+}
+
+// ObserveSend 统计下行消息写入耗时及失败次数
+func (m *Metrics) ObserveSend(sessionType string, err error, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.sendDurs.WithLabelValues(sessionType).Observe(duration.Seconds())
+	if err != nil {
+		m.sendFailures.WithLabelValues(sessionType).Inc()
+	}
+}
+
+// ObserveMulticast 统计multicast消息的接收者数量，以及没有连接到当前网关的接收者数量
+func (m *Metrics) ObserveMulticast(receivers, misses int) {
+	if m == nil {
+		return
+	}
+
+	m.multicastFanout.Observe(float64(receivers))
+	m.multicastMisses.Add(float64(misses))
+}
+
+// SetStatefulRoutes 设置有状态路由表内的会话数量
+func (m *Metrics) SetStatefulRoutes(count int) {
+	if m == nil {
+		return
+	}
+
+	m.statefulRoutes.Set(float64(count))
+}
+
+// IncrMessageQueue 队列消息统计
+func (m *Metrics) IncrMessageQueue(topic string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.queueTotal.WithLabelValues(topic).Inc()
+	m.queueDurs.WithLabelValues(topic).Observe(duration.Seconds())
+}
+
+// SetServiceConflicts 设置服务代码冲突的节点数量
+func (m *Metrics) SetServiceConflicts(serviceCode int32, count int) {
+	if m == nil {
+		return
+	}
+
+	m.serviceConflicts.WithLabelValues(strconv.Itoa(int(serviceCode))).Set(float64(count))
+}
+
+// IncrNodeEjection 统计被摘除的节点
+func (m *Metrics) IncrNodeEjection(reason string) {
+	if m == nil {
+		return
+	}
+
+	m.nodeEjections.WithLabelValues(reason).Inc()
+}
+
+// SetEjectedNodes 设置当前被摘除的节点数量
+func (m *Metrics) SetEjectedNodes(count int) {
+	if m == nil {
+		return
+	}
+
+	m.ejectedNodes.Set(float64(count))
+}
+
+// IncrWatchEvent 统计服务注册表的watch事件
+func (m *Metrics) IncrWatchEvent(eventType string) {
+	if m == nil {
+		return
+	}
+
+	m.watchEvents.WithLabelValues(eventType).Inc()
+}
+
+// SetNodes 设置每种状态的节点数量
+func (m *Metrics) SetNodes(counts map[string]int) {
+	if m == nil {
+		return
+	}
+
+	m.nodes.Reset()
+	for state, count := range counts {
+		m.nodes.WithLabelValues(state).Set(float64(count))
+	}
+}
+
+// SetGoPool 设置goroutine池的使用情况，waiting小于0表示未知
+func (m *Metrics) SetGoPool(running, capacity, waiting int) {
+	if m == nil {
+		return
+	}
+
+	m.goPoolRunning.Set(float64(running))
+	m.goPoolCapacity.Set(float64(capacity))
+	if waiting >= 0 {
+		m.goPoolWaiting.Set(float64(waiting))
+	}
+}
+
+// IncrGoPoolRejected 统计被goroutine池拒绝的任务
+func (m *Metrics) IncrGoPoolRejected() {
+	if m == nil {
+		return
+	}
+
+	m.goPoolRejected.Inc()
+}
@@ -2,14 +2,18 @@ package multicast
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
-	"github.com/joyparty/nodehub/internal/metrics"
 	"github.com/joyparty/nodehub/internal/mq"
 	"github.com/joyparty/nodehub/logger"
+	"github.com/joyparty/nodehub/metrics"
 	"github.com/joyparty/nodehub/proto/nh"
+	"github.com/joyparty/nodehub/tracing"
 	"github.com/nats-io/nats.go"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -18,7 +22,8 @@ type Queue = mq.Queue
 
 // Bus push message总线
 type Bus struct {
-	queue mq.Queue
+	queue   mq.Queue
+	metrics atomic.Pointer[metrics.Metrics]
 }
 
 // NewBus 构造函数
@@ -53,8 +58,22 @@ func NewRedisBus(client *redis.Client, options ...func(*Options)) *Bus {
 }
 
 // Publish 把消息发布到消息队列
-func (bus *Bus) Publish(ctx context.Context, message *nh.Multicast) error {
-	payload, err := proto.Marshal(message)
+func (bus *Bus) Publish(ctx context.Context, message *nh.Multicast) (err error) {
+	ctx, span := tracing.Start(ctx, "multicast.publish", trace.SpanKindProducer,
+		attribute.String("messaging.destination.name", bus.queue.Topic()),
+		attribute.Int("nodehub.multicast.receivers", len(message.GetReceiver())),
+		attribute.Int("nodehub.reply.service", int(message.GetContent().GetServiceCode())),
+		attribute.Int("nodehub.reply.code", int(message.GetContent().GetCode())),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	// 不修改调用方的消息
+	payload, err := proto.Marshal(&nh.Multicast{
+		Receiver: message.GetReceiver(),
+		Time:     message.GetTime(),
+		Content:  message.GetContent(),
+		Trace:    tracing.Inject(ctx),
+	})
 	if err != nil {
 		return err
 	}
@@ -74,15 +93,27 @@ func (bus *Bus) Subscribe(ctx context.Context, handler func(*nh.Multicast)) erro
 			if err := proto.Unmarshal(msg, n); err != nil {
 				logger.Error("unmarshal multicast message", "error", err)
 			} else {
+				_, span := tracing.Start(tracing.Extract(ctx, n.GetTrace()), "multicast.consume", trace.SpanKindConsumer,
+					attribute.String("messaging.destination.name", bus.queue.Topic()),
+					attribute.Int("nodehub.multicast.receivers", len(n.GetReceiver())),
+				)
 				handler(n)
+				span.End()
 
-				metrics.IncrMessageQueue(bus.queue.Topic(), time.Since(n.Time.AsTime()))
+				bus.metrics.Load().IncrMessageQueue(bus.queue.Topic(), time.Since(n.Time.AsTime()))
 			}
 		}
 	}()
 	return nil
 }
 
+// SetMetrics 设置消息队列统计使用的metrics，没有设置时不做统计
+//
+// 通过gateway.WithMulticast()配置给网关时，会自动设置为网关服务注册表的metrics
+func (bus *Bus) SetMetrics(m *metrics.Metrics) {
+	bus.metrics.Store(m)
+}
+
 // Close 关闭消息队列
 func (bus *Bus) Close() {
 	bus.queue.Close()
@@ -14,6 +14,7 @@ import (
 	"github.com/joyparty/nodehub/component/metrics"
 	"github.com/joyparty/nodehub/component/rpc"
 	"github.com/joyparty/nodehub/logger"
+	nhmetrics "github.com/joyparty/nodehub/metrics"
 	"github.com/joyparty/nodehub/proto/nh"
 	"github.com/oklog/ulid/v2"
 	"github.com/samber/lo"
@@ -77,11 +78,21 @@ func (n *Node) AddComponent(c ...Component) {
 		RegisterService(code int32, desc grpc.ServiceDesc, impl any, options ...rpc.Option) error
 	}
 
+	type metricsSetter interface {
+		SetMetrics(*nhmetrics.Metrics)
+	}
+
+	registered := false
 	for i := range c {
 		// 自动注入节点管理服务
-		if v, ok := c[i].(grpcServer); ok {
+		if v, ok := c[i].(grpcServer); ok && !registered {
 			_ = v.RegisterService(nh.NodeServiceCode, nh.Node_ServiceDesc, &nodeService{node: n})
-			break
+			registered = true
+		}
+
+		// 使用服务注册表的metrics
+		if v, ok := c[i].(metricsSetter); ok && n.registry != nil {
+			v.SetMetrics(n.registry.Metrics())
 		}
 	}
 	n.components = append(n.components, c...)
@@ -98,6 +109,11 @@ func (n *Node) Serve(ctx context.Context) error {
 		return fmt.Errorf("node grpc service not register")
 	}
 
+	// 服务代码被集群内其它服务使用时，注册之后网关会把请求转发到错误的服务
+	if err := n.registry.CheckServiceConflict(entry); err != nil {
+		return err
+	}
+
 	if err := n.startAll(ctx); err != nil {
 		return fmt.Errorf("start all server, %w", err)
 	}
@@ -306,3 +322,16 @@ func WithState(state cluster.NodeState) NodeOption {
 		n.entry.State = state
 	}
 }
+
+// WithLabels 设置节点标签，可以通过cluster.Selector筛选节点
+func WithLabels(labels map[string]string) NodeOption {
+	return func(n *Node) {
+		if n.entry.Labels == nil {
+			n.entry.Labels = make(map[string]string, len(labels))
+		}
+
+		for k, v := range labels {
+			n.entry.Labels[k] = v
+		}
+	}
+}
@@ -67,6 +67,8 @@ func ResetRequest(req *Request) {
 	req.NodeId = ""
 	req.ServiceCode = 0
 	req.Method = ""
+	req.Unreliable = false
+	req.Timeout = 0
 
 	if len(req.Data) > 0 {
 		req.Data = req.Data[:0]
@@ -78,6 +80,7 @@ func ResetReply(resp *Reply) {
 	resp.RequestId = 0
 	resp.ServiceCode = 0
 	resp.Code = 0
+	resp.Unreliable = false
 
 	if len(resp.Data) > 0 {
 		resp.Data = resp.Data[:0]
@@ -100,6 +103,14 @@ func (x *Request) LogValue() slog.Value {
 		attrs = append(attrs, slog.Bool("noReply", true))
 	}
 
+	if x.GetUnreliable() {
+		attrs = append(attrs, slog.Bool("unreliable", true))
+	}
+
+	if timeout := x.GetTimeout(); timeout > 0 {
+		attrs = append(attrs, slog.Int("timeout", int(timeout)))
+	}
+
 	return slog.GroupValue(attrs...)
 }
 
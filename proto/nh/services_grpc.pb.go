@@ -22,10 +22,12 @@ const _ = grpc.SupportPackageIsVersion7
 const (
 	Gateway_IsSessionExist_FullMethodName      = "/nodehub.Gateway/IsSessionExist"
 	Gateway_SessionCount_FullMethodName        = "/nodehub.Gateway/SessionCount"
+	Gateway_ListSessions_FullMethodName        = "/nodehub.Gateway/ListSessions"
 	Gateway_CloseSession_FullMethodName        = "/nodehub.Gateway/CloseSession"
 	Gateway_SetServiceRoute_FullMethodName     = "/nodehub.Gateway/SetServiceRoute"
 	Gateway_RemoveServiceRoute_FullMethodName  = "/nodehub.Gateway/RemoveServiceRoute"
 	Gateway_ReplaceServiceRoute_FullMethodName = "/nodehub.Gateway/ReplaceServiceRoute"
+	Gateway_GetServiceRoutes_FullMethodName    = "/nodehub.Gateway/GetServiceRoutes"
 	Gateway_SendReply_FullMethodName           = "/nodehub.Gateway/SendReply"
 )
 
@@ -37,6 +39,8 @@ type GatewayClient interface {
 	IsSessionExist(ctx context.Context, in *IsSessionExistRequest, opts ...grpc.CallOption) (*IsSessionExistResponse, error)
 	// 会话数量
 	SessionCount(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SessionCountResponse, error)
+	// 会话列表，按会话ID排序分页
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
 	// 关闭会话连接，踢下线
 	CloseSession(ctx context.Context, in *CloseSessionRequest, opts ...grpc.CallOption) (*CloseSessionResponse, error)
 	// 修改状态服务路由
@@ -45,6 +49,8 @@ type GatewayClient interface {
 	RemoveServiceRoute(ctx context.Context, in *RemoveServiceRouteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// 替换状态服务路由节点
 	ReplaceServiceRoute(ctx context.Context, in *ReplaceServiceRouteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// 查询会话的状态服务路由
+	GetServiceRoutes(ctx context.Context, in *GetServiceRoutesRequest, opts ...grpc.CallOption) (*GetServiceRoutesResponse, error)
 	// 向指定会话推送消息
 	SendReply(ctx context.Context, in *SendReplyRequest, opts ...grpc.CallOption) (*SendReplyResponse, error)
 }
@@ -75,6 +81,15 @@ func (c *gatewayClient) SessionCount(ctx context.Context, in *emptypb.Empty, opt
 	return out, nil
 }
 
+func (c *gatewayClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, Gateway_ListSessions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gatewayClient) CloseSession(ctx context.Context, in *CloseSessionRequest, opts ...grpc.CallOption) (*CloseSessionResponse, error) {
 	out := new(CloseSessionResponse)
 	err := c.cc.Invoke(ctx, Gateway_CloseSession_FullMethodName, in, out, opts...)
@@ -111,6 +126,15 @@ func (c *gatewayClient) ReplaceServiceRoute(ctx context.Context, in *ReplaceServ
 	return out, nil
 }
 
+func (c *gatewayClient) GetServiceRoutes(ctx context.Context, in *GetServiceRoutesRequest, opts ...grpc.CallOption) (*GetServiceRoutesResponse, error) {
+	out := new(GetServiceRoutesResponse)
+	err := c.cc.Invoke(ctx, Gateway_GetServiceRoutes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *gatewayClient) SendReply(ctx context.Context, in *SendReplyRequest, opts ...grpc.CallOption) (*SendReplyResponse, error) {
 	out := new(SendReplyResponse)
 	err := c.cc.Invoke(ctx, Gateway_SendReply_FullMethodName, in, out, opts...)
@@ -128,6 +152,8 @@ type GatewayServer interface {
 	IsSessionExist(context.Context, *IsSessionExistRequest) (*IsSessionExistResponse, error)
 	// 会话数量
 	SessionCount(context.Context, *emptypb.Empty) (*SessionCountResponse, error)
+	// 会话列表，按会话ID排序分页
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
 	// 关闭会话连接，踢下线
 	CloseSession(context.Context, *CloseSessionRequest) (*CloseSessionResponse, error)
 	// 修改状态服务路由
@@ -136,6 +162,8 @@ type GatewayServer interface {
 	RemoveServiceRoute(context.Context, *RemoveServiceRouteRequest) (*emptypb.Empty, error)
 	// 替换状态服务路由节点
 	ReplaceServiceRoute(context.Context, *ReplaceServiceRouteRequest) (*emptypb.Empty, error)
+	// 查询会话的状态服务路由
+	GetServiceRoutes(context.Context, *GetServiceRoutesRequest) (*GetServiceRoutesResponse, error)
 	// 向指定会话推送消息
 	SendReply(context.Context, *SendReplyRequest) (*SendReplyResponse, error)
 	mustEmbedUnimplementedGatewayServer()
@@ -151,6 +179,9 @@ func (UnimplementedGatewayServer) IsSessionExist(context.Context, *IsSessionExis
 func (UnimplementedGatewayServer) SessionCount(context.Context, *emptypb.Empty) (*SessionCountResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SessionCount not implemented")
 }
+func (UnimplementedGatewayServer) ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
 func (UnimplementedGatewayServer) CloseSession(context.Context, *CloseSessionRequest) (*CloseSessionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CloseSession not implemented")
 }
@@ -163,6 +194,9 @@ func (UnimplementedGatewayServer) RemoveServiceRoute(context.Context, *RemoveSer
 func (UnimplementedGatewayServer) ReplaceServiceRoute(context.Context, *ReplaceServiceRouteRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReplaceServiceRoute not implemented")
 }
+func (UnimplementedGatewayServer) GetServiceRoutes(context.Context, *GetServiceRoutesRequest) (*GetServiceRoutesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServiceRoutes not implemented")
+}
 func (UnimplementedGatewayServer) SendReply(context.Context, *SendReplyRequest) (*SendReplyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendReply not implemented")
 }
@@ -215,6 +249,24 @@ func _Gateway_SessionCount_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Gateway_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Gateway_CloseSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CloseSessionRequest)
 	if err := dec(in); err != nil {
@@ -287,6 +339,24 @@ func _Gateway_ReplaceServiceRoute_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Gateway_GetServiceRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServiceRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServer).GetServiceRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Gateway_GetServiceRoutes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServer).GetServiceRoutes(ctx, req.(*GetServiceRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Gateway_SendReply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SendReplyRequest)
 	if err := dec(in); err != nil {
@@ -320,6 +390,10 @@ var Gateway_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SessionCount",
 			Handler:    _Gateway_SessionCount_Handler,
 		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _Gateway_ListSessions_Handler,
+		},
 		{
 			MethodName: "CloseSession",
 			Handler:    _Gateway_CloseSession_Handler,
@@ -336,6 +410,10 @@ var Gateway_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReplaceServiceRoute",
 			Handler:    _Gateway_ReplaceServiceRoute_Handler,
 		},
+		{
+			MethodName: "GetServiceRoutes",
+			Handler:    _Gateway_GetServiceRoutes_Handler,
+		},
 		{
 			MethodName: "SendReply",
 			Handler:    _Gateway_SendReply_Handler,
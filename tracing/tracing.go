@@ -0,0 +1,84 @@
+// Package tracing 基于OpenTelemetry的链路追踪
+//
+// 框架内的网关会话、请求转发、gRPC服务、事件以及主动下行消息都会创建span，
+// 默认使用otel全局的TracerProvider，没有初始化时不产生任何数据
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/status"
+)
+
+const instrumentationName = "github.com/joyparty/nodehub"
+
+// Init 使用exporter初始化全局TracerProvider，以及W3C trace context传播格式
+//
+// exporter可以使用任意实现，例如otlptracegrpc.New()创建的OTLP exporter，
+// 进程退出之前需要调用返回值的Shutdown()方法，把缓存的span发送出去
+//
+// Example:
+//
+//	exporter, _ := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint("localhost:4317"), otlptracegrpc.WithInsecure())
+//	tp := tracing.Init(exporter, sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("gateway"))))
+//	defer tp.Shutdown(context.Background())
+func Init(exporter sdktrace.SpanExporter, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	tp := sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{
+		sdktrace.WithBatcher(exporter),
+	}, opts...)...)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return tp
+}
+
+// Tracer 框架使用的tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 创建span
+func Start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// End 结束span，err不为空时记录错误
+func End(span trace.Span, err error) {
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(s.Code())))
+			span.SetStatus(codes.Error, s.Message())
+		} else {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// Inject 把ctx内的追踪信息写入map，用于通过消息队列传递
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract 从消息携带的追踪信息恢复ctx
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
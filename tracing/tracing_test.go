@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	prevTP, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return exporter
+}
+
+func findSpan(t *testing.T, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("span %q not found", name)
+	return tracetest.SpanStub{}
+}
+
+func TestPropagation(t *testing.T) {
+	exporter := setupTracing(t)
+
+	ctx, span := Start(context.Background(), "publish", trace.SpanKindProducer)
+	carrier := Inject(ctx)
+	span.End()
+
+	if len(carrier) == 0 {
+		t.Fatal("trace context not injected")
+	}
+
+	_, span = Start(Extract(context.Background(), carrier), "consume", trace.SpanKindConsumer)
+	span.End()
+
+	publish, consume := findSpan(t, exporter, "publish"), findSpan(t, exporter, "consume")
+	if consume.Parent.SpanID() != publish.SpanContext.SpanID() {
+		t.Fatal("consume span is not child of publish span")
+	}
+
+	if Inject(context.Background()) != nil {
+		t.Fatal("unexpected carrier without span")
+	}
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	exporter := setupTracing(t)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(UnaryServerInterceptor()))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, root := Start(context.Background(), "request", trace.SpanKindInternal)
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	root.End()
+
+	const name = "grpc.health.v1.Health/Check"
+	var clientSpan, serverSpan tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		if span.Name != name {
+			continue
+		}
+
+		switch span.SpanKind {
+		case trace.SpanKindClient:
+			clientSpan = span
+		case trace.SpanKindServer:
+			serverSpan = span
+		}
+	}
+
+	request := findSpan(t, exporter, "request")
+	if !clientSpan.SpanContext.IsValid() || !serverSpan.SpanContext.IsValid() {
+		t.Fatal("grpc spans not recorded")
+	} else if clientSpan.Parent.SpanID() != request.SpanContext.SpanID() {
+		t.Fatal("client span is not child of request span")
+	} else if serverSpan.Parent.SpanID() != clientSpan.SpanContext.SpanID() {
+		t.Fatal("server span is not child of client span")
+	} else if serverSpan.SpanContext.TraceID() != request.SpanContext.TraceID() {
+		t.Fatal("trace id not propagated")
+	}
+}